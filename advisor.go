@@ -0,0 +1,249 @@
+package kivik
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/go-kivik/kivik/errors"
+)
+
+// IndexAdvice is the result of AdviseIndex.
+type IndexAdvice struct {
+	// Index is the existing index which can satisfy the query, or nil if
+	// there is none.
+	Index *Index
+	// Definition is a JSON index definition which would satisfy the query,
+	// suitable for passing to CreateIndex. It is nil if Index is set, or if
+	// the query cannot benefit from a JSON index.
+	Definition map[string]interface{}
+}
+
+// NeedsIndex returns true if no existing index can satisfy the query.
+func (a *IndexAdvice) NeedsIndex() bool {
+	return a.Index == nil
+}
+
+// fieldUse describes how a selector constrains a single field.
+type fieldUse int
+
+const (
+	// useExists means the field must exist, but the condition cannot be
+	// used to restrict an index range, as with $regex or $ne.
+	useExists fieldUse = iota + 1
+	// useRange means the field is constrained by $gt, $gte, $lt or $lte.
+	useRange
+	// useEquality means the field is constrained by $eq or implicit
+	// equality.
+	useEquality
+)
+
+var rangeOperators = map[string]bool{
+	"$gt":  true,
+	"$gte": true,
+	"$lt":  true,
+	"$lte": true,
+}
+
+type indexQuery struct {
+	Selector map[string]interface{} `json:"selector"`
+	Sort     []interface{}          `json:"sort"`
+}
+
+// AdviseIndex examines a query, in the format accepted by Find, and the
+// indexes returned by GetIndexes, and reports whether one of those indexes
+// can satisfy the query. If none can, it proposes a JSON index definition
+// which could.
+//
+// An index can satisfy a query if every indexed field is required by the
+// selector, the first indexed field is usable to restrict the range of keys
+// scanned, and any sort fields follow the fields constrained to a single
+// value. The proposed index lists equality fields first, then sort fields,
+// then range fields, as recommended by the CouchDB documentation. Note that
+// CouchDB also requires sort fields to appear in the selector.
+//
+// If no field in the query can restrict the range of keys scanned, as when
+// the selector uses only conditions such as $regex or $ne, any index of
+// required fields is accepted, and one of all such fields is proposed, as it
+// still avoids examining documents which lack them.
+//
+// Only fields constrained at the top level of the selector, or within a
+// top-level $and, are considered. Conditions under $or, $nor or $not cannot
+// be served by a JSON index.
+func AdviseIndex(query interface{}, indexes []Index) (*IndexAdvice, error) {
+	var q indexQuery
+	if err := remarshal(query, &q); err != nil {
+		return nil, errors.WrapStatus(StatusBadRequest, err)
+	}
+	if q.Selector == nil {
+		return nil, missingArg("selector")
+	}
+	uses := make(map[string]fieldUse)
+	collectFieldUses(uses, "", q.Selector)
+	sortFields, err := sortFieldNames(q.Sort)
+	if err != nil {
+		return nil, err
+	}
+	for i := range indexes {
+		if indexSatisfies(&indexes[i], uses, sortFields) {
+			return &IndexAdvice{Index: &indexes[i]}, nil
+		}
+	}
+	fields := proposeIndexFields(uses, sortFields)
+	if len(fields) == 0 {
+		return &IndexAdvice{}, nil
+	}
+	return &IndexAdvice{
+		Definition: map[string]interface{}{"fields": fields},
+	}, nil
+}
+
+// collectFieldUses records the strongest use of each field required by the
+// selector.
+func collectFieldUses(uses map[string]fieldUse, prefix string, sel map[string]interface{}) {
+	for key, value := range sel {
+		if strings.HasPrefix(key, "$") {
+			if key != "$and" {
+				continue
+			}
+			list, _ := value.([]interface{})
+			for _, item := range list {
+				if sub, ok := item.(map[string]interface{}); ok {
+					collectFieldUses(uses, prefix, sub)
+				}
+			}
+			continue
+		}
+		field := prefix + key
+		cond, ok := value.(map[string]interface{})
+		if !ok || len(cond) == 0 {
+			setFieldUse(uses, field, useEquality)
+			continue
+		}
+		subFields := make(map[string]interface{})
+		for op, arg := range cond {
+			switch {
+			case !strings.HasPrefix(op, "$"):
+				subFields[op] = arg
+			case op == "$eq":
+				setFieldUse(uses, field, useEquality)
+			case rangeOperators[op]:
+				setFieldUse(uses, field, useRange)
+			case op == "$exists" && arg == false:
+				// A field which must not exist cannot be indexed.
+			default:
+				setFieldUse(uses, field, useExists)
+			}
+		}
+		if len(subFields) > 0 {
+			collectFieldUses(uses, field+".", subFields)
+		}
+	}
+}
+
+func setFieldUse(uses map[string]fieldUse, field string, use fieldUse) {
+	if use > uses[field] {
+		uses[field] = use
+	}
+}
+
+func sortFieldNames(sortSpec []interface{}) ([]string, error) {
+	fields := make([]string, 0, len(sortSpec))
+	for _, item := range sortSpec {
+		switch t := item.(type) {
+		case string:
+			fields = append(fields, t)
+		case map[string]interface{}:
+			if len(t) != 1 {
+				return nil, errors.Status(StatusBadRequest, "kivik: each sort field must have exactly one direction")
+			}
+			for name := range t {
+				fields = append(fields, name)
+			}
+		default:
+			return nil, errors.Status(StatusBadRequest, "kivik: invalid sort field")
+		}
+	}
+	return fields, nil
+}
+
+func indexSatisfies(index *Index, uses map[string]fieldUse, sortFields []string) bool {
+	if index.Type != "json" {
+		return false
+	}
+	fields := index.Fields()
+	if len(fields) == 0 {
+		return false
+	}
+	for _, field := range fields {
+		if uses[field] == 0 {
+			return false
+		}
+	}
+	// Leading equality fields don't affect ordering, so sort fields may
+	// follow them.
+	remaining := fields
+	for len(remaining) > 0 && uses[remaining[0]] == useEquality && !stringIn(remaining[0], sortFields) {
+		remaining = remaining[1:]
+	}
+	if len(sortFields) > len(remaining) {
+		return false
+	}
+	for i, field := range sortFields {
+		if remaining[i] != field {
+			return false
+		}
+	}
+	return uses[fields[0]] >= useRange || len(sortFields) > 0 || !restrictable(uses)
+}
+
+// restrictable returns true if any field is constrained in a way that can
+// restrict the range of index keys scanned.
+func restrictable(uses map[string]fieldUse) bool {
+	for _, use := range uses {
+		if use >= useRange {
+			return true
+		}
+	}
+	return false
+}
+
+func proposeIndexFields(uses map[string]fieldUse, sortFields []string) []interface{} {
+	var equality, ranges, other []string
+	for field, use := range uses {
+		if stringIn(field, sortFields) {
+			continue
+		}
+		switch use {
+		case useEquality:
+			equality = append(equality, field)
+		case useRange:
+			ranges = append(ranges, field)
+		default:
+			other = append(other, field)
+		}
+	}
+	sort.Strings(equality)
+	sort.Strings(ranges)
+	sort.Strings(other)
+	if len(sortFields) == 0 && !restrictable(uses) {
+		// None of the fields can restrict the range scanned, but indexing
+		// the required fields still avoids examining unrelated documents.
+		equality = other
+	}
+	fields := make([]interface{}, 0, len(equality)+len(sortFields)+len(ranges))
+	for _, group := range [][]string{equality, sortFields, ranges} {
+		for _, field := range group {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+func stringIn(str string, list []string) bool {
+	for _, s := range list {
+		if s == str {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,137 @@
+package kivik
+
+import (
+	"testing"
+
+	"github.com/flimzy/diff"
+	"github.com/flimzy/testy"
+)
+
+func TestAdviseIndex(t *testing.T) {
+	jsonIndex := func(name string, fields ...interface{}) Index {
+		return Index{
+			DesignDoc:  "_design/" + name,
+			Name:       name,
+			Type:       "json",
+			Definition: map[string]interface{}{"fields": fields},
+		}
+	}
+	allDocs := Index{Name: "_all_docs", Type: "special", Definition: map[string]interface{}{"fields": []interface{}{map[string]interface{}{"_id": "asc"}}}}
+	tests := []struct {
+		name     string
+		query    interface{}
+		indexes  []Index
+		expected *IndexAdvice
+		status   int
+		err      string
+	}{
+		{
+			name:   "invalid JSON",
+			query:  "{",
+			status: StatusBadRequest,
+			err:    "unexpected end of JSON input",
+		},
+		{
+			name:   "no selector",
+			query:  `{"limit":1}`,
+			status: StatusBadRequest,
+			err:    "kivik: selector required",
+		},
+		{
+			name:   "invalid sort",
+			query:  `{"selector":{"a":1},"sort":[1]}`,
+			status: StatusBadRequest,
+			err:    "kivik: invalid sort field",
+		},
+		{
+			name:    "only special index",
+			query:   map[string]interface{}{"selector": map[string]interface{}{"name": "Bob"}},
+			indexes: []Index{allDocs},
+			expected: &IndexAdvice{
+				Definition: map[string]interface{}{"fields": []interface{}{"name"}},
+			},
+		},
+		{
+			name:     "existing index",
+			query:    `{"selector":{"name":"Bob","age":{"$gt":20}}}`,
+			indexes:  []Index{allDocs, jsonIndex("foo", map[string]interface{}{"name": "asc"}, "age")},
+			expected: &IndexAdvice{Index: &Index{DesignDoc: "_design/foo", Name: "foo", Type: "json", Definition: map[string]interface{}{"fields": []interface{}{map[string]interface{}{"name": "asc"}, "age"}}}},
+		},
+		{
+			name:    "index field not in selector",
+			query:   `{"selector":{"name":"Bob"}}`,
+			indexes: []Index{jsonIndex("foo", "name", "age")},
+			expected: &IndexAdvice{
+				Definition: map[string]interface{}{"fields": []interface{}{"name"}},
+			},
+		},
+		{
+			name:    "first field not indexable",
+			query:   `{"selector":{"name":{"$regex":"^B"},"age":{"$gt":20}}}`,
+			indexes: []Index{jsonIndex("foo", "name", "age")},
+			expected: &IndexAdvice{
+				Definition: map[string]interface{}{"fields": []interface{}{"age"}},
+			},
+		},
+		{
+			name:     "sort after equality",
+			query:    `{"selector":{"type":"user","age":{"$gt":20}},"sort":[{"age":"desc"}]}`,
+			indexes:  []Index{jsonIndex("foo", "type", "age")},
+			expected: &IndexAdvice{Index: &Index{DesignDoc: "_design/foo", Name: "foo", Type: "json", Definition: map[string]interface{}{"fields": []interface{}{"type", "age"}}}},
+		},
+		{
+			name:    "sort mismatch",
+			query:   `{"selector":{"type":"user","age":{"$gt":20},"name":{"$gt":null}},"sort":["name"]}`,
+			indexes: []Index{jsonIndex("foo", "type", "age", "name")},
+			expected: &IndexAdvice{
+				Definition: map[string]interface{}{"fields": []interface{}{"type", "name", "age"}},
+			},
+		},
+		{
+			name:  "nested and sub-fields",
+			query: `{"selector":{"$and":[{"address":{"city":"Paris"}},{"age":{"$lte":30}}],"$or":[{"a":1},{"b":2}]}}`,
+			expected: &IndexAdvice{
+				Definition: map[string]interface{}{"fields": []interface{}{"address.city", "age"}},
+			},
+		},
+		{
+			name:  "exists false ignored",
+			query: `{"selector":{"deleted":{"$exists":false},"name":{"$regex":"x"}}}`,
+			expected: &IndexAdvice{
+				Definition: map[string]interface{}{"fields": []interface{}{"name"}},
+			},
+		},
+		{
+			name:     "existing index without range",
+			query:    `{"selector":{"name":{"$regex":"^B"}}}`,
+			indexes:  []Index{jsonIndex("foo", "name")},
+			expected: &IndexAdvice{Index: &Index{DesignDoc: "_design/foo", Name: "foo", Type: "json", Definition: map[string]interface{}{"fields": []interface{}{"name"}}}},
+		},
+		{
+			name:     "nothing indexable",
+			query:    []byte(`{"selector":{"$or":[{"a":1},{"b":2}]}}`),
+			expected: &IndexAdvice{},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := AdviseIndex(test.query, test.indexes)
+			testy.StatusError(t, test.err, test.status, err)
+			if d := diff.Interface(test.expected, result); d != nil {
+				t.Error(d)
+			}
+			if result.Definition == nil {
+				return
+			}
+			// Once the proposed index exists, it must be accepted.
+			fields, _ := result.Definition["fields"].([]interface{})
+			proposed, err := AdviseIndex(test.query, append(test.indexes, jsonIndex("proposed", fields...)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if proposed.NeedsIndex() || proposed.Index.Name != "proposed" {
+				t.Errorf("Proposed index not accepted: %v", proposed)
+			}
+		})
+	}
+}
@@ -2,12 +2,18 @@ package kivik
 
 import (
 	"bytes"
+	"crypto/md5"
 	"encoding/base64"
 	"encoding/json"
+	"hash"
 	"io"
 	"io/ioutil"
+	"reflect"
+	"sort"
+	"strings"
 
 	"github.com/go-kivik/kivik/driver"
+	"github.com/go-kivik/kivik/errors"
 )
 
 // Attachments is a collection of one or more file attachments.
@@ -16,6 +22,109 @@ type Attachments map[string]*Attachment
 // MD5sum is a 128-bit MD5 checksum.
 type MD5sum [16]byte
 
+const md5DigestPrefix = "md5-"
+
+// Digest returns the checksum in the format used by CouchDB for attachment
+// digests, for example "md5-iMaiC8wqiFlD2NjLTemvCQ==". The result may be
+// assigned to an Attachment's Digest field, to have PutAttachment verify the
+// upload against a known checksum.
+func (s MD5sum) Digest() string {
+	return md5DigestPrefix + base64.StdEncoding.EncodeToString(s[:])
+}
+
+// parseMD5Digest parses a CouchDB attachment digest. ok is false if digest is
+// not a valid MD5 digest, such as when it is empty.
+func parseMD5Digest(digest string) (sum MD5sum, ok bool) {
+	if !strings.HasPrefix(digest, md5DigestPrefix) {
+		return sum, false
+	}
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(digest, md5DigestPrefix))
+	if err != nil || len(raw) != len(sum) {
+		return sum, false
+	}
+	copy(sum[:], raw)
+	return sum, true
+}
+
+func digestMismatch(expected, actual MD5sum) error {
+	return errors.Statusf(StatusBadResponse, "kivik: attachment digest mismatch: expected %s, got %s", expected.Digest(), actual.Digest())
+}
+
+// compressibleTypes are the content types which CouchDB compresses by
+// default. See the attachments/compressible_types configuration option.
+var compressibleTypes = []string{"text/*", "application/javascript", "application/json", "application/xml"}
+
+// compressible returns true if CouchDB compresses attachments of contentType
+// by default.
+func compressible(contentType string) bool {
+	mediaType := strings.ToLower(strings.TrimSpace(strings.SplitN(contentType, ";", 2)[0]))
+	for _, t := range compressibleTypes {
+		if mediaType == t || (strings.HasSuffix(t, "/*") && strings.HasPrefix(mediaType, strings.TrimSuffix(t, "*"))) {
+			return true
+		}
+	}
+	return false
+}
+
+// storedIdentity returns true if the attachment is known to be stored without
+// compression. Only then is the Digest reported by the server the MD5 sum of
+// the content as read by the caller; for compressed attachments, it is the sum
+// of the compressed data, while HTTP clients typically decompress the content
+// transparently. As drivers may not report the encoding, attachments of
+// compressible types are never assumed to be stored uncompressed.
+func (a *Attachment) storedIdentity() bool {
+	if a.ContentEncoding != "" {
+		return false
+	}
+	if a.EncodedLength != 0 && a.EncodedLength != a.Size {
+		return false
+	}
+	return !compressible(a.ContentType)
+}
+
+// md5Reader computes the MD5 checksum of the data read from it.
+type md5Reader struct {
+	io.ReadCloser
+	h hash.Hash
+}
+
+var _ io.ReadCloser = &md5Reader{}
+
+func newMD5Reader(r io.ReadCloser) *md5Reader {
+	return &md5Reader{ReadCloser: r, h: md5.New()}
+}
+
+func (r *md5Reader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	_, _ = r.h.Write(p[:n])
+	return n, err
+}
+
+func (r *md5Reader) sum() MD5sum {
+	var sum MD5sum
+	copy(sum[:], r.h.Sum(nil))
+	return sum
+}
+
+// verifyingReader computes the MD5 checksum of the data read from it, and
+// returns an error in place of io.EOF if it does not match the expected value.
+type verifyingReader struct {
+	*md5Reader
+	expected MD5sum
+}
+
+var _ io.ReadCloser = &verifyingReader{}
+
+func (r *verifyingReader) Read(p []byte) (int, error) {
+	n, err := r.md5Reader.Read(p)
+	if err == io.EOF {
+		if sum := r.sum(); sum != r.expected {
+			return n, digestMismatch(r.expected, sum)
+		}
+	}
+	return n, err
+}
+
 // Attachment represents a file attachment on a CouchDB document.
 type Attachment struct {
 	// Filiename is the name of the attachment.
@@ -74,6 +183,50 @@ func (a *Attachment) validate() error {
 	return nil
 }
 
+// seek discards the first offset bytes of the attachment's content, and
+// limits the remainder to length bytes, if length is not negative. Size is
+// updated to reflect the range.
+func (a *Attachment) seek(offset, length int64) error {
+	if a.Content == nil {
+		a.Content = nilContent
+	}
+	if a.Size >= 0 && offset > 0 && offset >= a.Size {
+		return rangeNotSatisfiable(offset, a.Size)
+	}
+	if n, err := io.CopyN(ioutil.Discard, a.Content, offset); err != nil {
+		if err == io.EOF {
+			return rangeNotSatisfiable(offset, n)
+		}
+		return err
+	}
+	if a.Size >= 0 {
+		a.Size -= offset
+		if length >= 0 && length < a.Size {
+			a.Size = length
+		}
+	}
+	if length >= 0 {
+		a.Content = &limitedReadCloser{
+			Reader: io.LimitReader(a.Content, length),
+			Closer: a.Content,
+		}
+	}
+	return nil
+}
+
+func rangeNotSatisfiable(offset, size int64) error {
+	return errors.Statusf(StatusRequestedRangeNotSatisfiable, "kivik: offset %d beyond attachment size %d", offset, size)
+}
+
+// limitedReadCloser limits reads from an io.ReadCloser, while still closing
+// the underlying reader.
+type limitedReadCloser struct {
+	io.Reader
+	io.Closer
+}
+
+var _ io.ReadCloser = &limitedReadCloser{}
+
 type jsonAttachment struct {
 	ContentType string `json:"content_type"`
 	Data        string `json:"data"`
@@ -124,6 +277,16 @@ func (a *Attachment) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// filenames returns the filenames of the attachments, sorted.
+func (a Attachments) filenames() []string {
+	filenames := make([]string, 0, len(a))
+	for filename := range a {
+		filenames = append(filenames, filename)
+	}
+	sort.Strings(filenames)
+	return filenames
+}
+
 // UnmarshalJSON implements the json.Unmarshaler interface for a collection of
 // Attachments.
 func (a *Attachments) UnmarshalJSON(data []byte) error {
@@ -142,19 +305,172 @@ func (a *Attachments) UnmarshalJSON(data []byte) error {
 // a multi-part Get request.
 type AttachmentsIterator struct {
 	atti driver.Attachments
+	// current is the content of the attachment most recently returned by
+	// Next.
+	current *iteratorContent
+}
+
+// iteratorContent wraps the content of an attachment returned by
+// AttachmentsIterator.Next. Closing it discards any unread content, so that
+// the stream is positioned at the next attachment, and subsequent calls to
+// Close do nothing.
+type iteratorContent struct {
+	io.ReadCloser
+	closed bool
+	err    error
+}
+
+var _ io.ReadCloser = &iteratorContent{}
+
+func (c *iteratorContent) Close() error {
+	if c.closed {
+		return c.err
+	}
+	c.closed = true
+	if _, err := io.Copy(ioutil.Discard, c.ReadCloser); err != nil {
+		_ = c.ReadCloser.Close()
+		c.err = err
+		return err
+	}
+	c.err = c.ReadCloser.Close()
+	return c.err
 }
 
-// Next returns the next attachment in the stream. io.EOF will be
-// returned when there are no more attachments.
+// Next returns the next attachment in the stream, with its complete metadata.
+// io.EOF will be returned when there are no more attachments.
+//
+// Any unread content of the attachment previously returned by Next is
+// skipped, and may no longer be read.
 func (i *AttachmentsIterator) Next() (*Attachment, error) {
+	if err := i.Skip(); err != nil {
+		return nil, err
+	}
 	att := new(driver.Attachment)
 	if err := i.atti.Next(att); err != nil {
 		return nil, err
 	}
-	return &Attachment{
-		Filename:    att.Filename,
-		ContentType: att.ContentType,
-		Content:     att.Content,
-		Size:        att.Size,
-	}, nil
+	a := Attachment(*att)
+	if a.Content != nil {
+		i.current = &iteratorContent{ReadCloser: a.Content}
+		a.Content = i.current
+	}
+	return &a, nil
+}
+
+// Skip discards any unread content of the attachment most recently returned
+// by Next, and closes it, so that the caller needn't read attachments it is
+// not interested in. It is called implicitly by Next. Closing the content
+// has the same effect, in which case Skip does nothing.
+func (i *AttachmentsIterator) Skip() error {
+	if i.current == nil {
+		return nil
+	}
+	content := i.current
+	i.current = nil
+	if content.closed {
+		return nil
+	}
+	return content.Close()
+}
+
+// Close closes the iterator, and any remaining unread attachments.
+func (i *AttachmentsIterator) Close() error {
+	if i.current != nil && !i.current.closed {
+		// The stream is being abandoned, so the content needn't be drained.
+		i.current.closed = true
+		_ = i.current.ReadCloser.Close()
+	}
+	i.current = nil
+	return i.atti.Close()
+}
+
+var (
+	attachmentsType    = reflect.TypeOf(Attachments{})
+	attachmentsPtrType = reflect.TypeOf(&Attachments{})
+)
+
+// extractAttachments returns doc as a map without its _attachments field, and
+// the attachments themselves, if doc is a map or struct carrying Attachments
+// in its _attachments field. atts is nil if doc carries no attachments.
+func extractAttachments(doc interface{}) (stripped map[string]interface{}, atts Attachments, err error) {
+	if m, ok := doc.(map[string]interface{}); ok {
+		atts = toAttachments(m["_attachments"])
+		if len(atts) == 0 {
+			return nil, nil, nil
+		}
+		stripped = make(map[string]interface{}, len(m))
+		for k, v := range m {
+			stripped[k] = v
+		}
+		delete(stripped, "_attachments")
+		return stripped, atts, nil
+	}
+	v := reflect.ValueOf(doc)
+	for v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil, nil, nil
+	}
+	field := attachmentsField(v.Type())
+	if field < 0 {
+		return nil, nil, nil
+	}
+	atts = toAttachments(v.Field(field).Interface())
+	if len(atts) == 0 {
+		return nil, nil, nil
+	}
+	// Marshal a copy of doc, with the attachments removed, to avoid reading
+	// their content.
+	c := reflect.New(v.Type()).Elem()
+	c.Set(v)
+	c.Field(field).Set(reflect.Zero(v.Type().Field(field).Type))
+	if err := remarshal(c.Interface(), &stripped); err != nil {
+		return nil, nil, errors.WrapStatus(StatusBadRequest, err)
+	}
+	delete(stripped, "_attachments")
+	return stripped, atts, nil
+}
+
+// attachmentsField returns the index of the exported field of t which is
+// marshaled as _attachments, and is of type Attachments or *Attachments, or -1
+// if there is none.
+func attachmentsField(t reflect.Type) int {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" || (f.Type != attachmentsType && f.Type != attachmentsPtrType) {
+			continue
+		}
+		if name := strings.Split(f.Tag.Get("json"), ",")[0]; name == "_attachments" {
+			return i
+		}
+	}
+	return -1
+}
+
+func toAttachments(i interface{}) Attachments {
+	switch t := i.(type) {
+	case Attachments:
+		return t
+	case *Attachments:
+		if t != nil {
+			return *t
+		}
+	}
+	return nil
+}
+
+// driverAttachments converts atts to driver attachments, sorted by filename.
+func driverAttachments(atts Attachments) []*driver.Attachment {
+	filenames := atts.filenames()
+	result := make([]*driver.Attachment, len(filenames))
+	for i, filename := range filenames {
+		a := driver.Attachment(*atts[filename])
+		a.Filename = filename
+		if a.Content == nil {
+			a.Content = nilContent
+		}
+		result[i] = &a
+	}
+	return result
 }
@@ -2,6 +2,8 @@ package kivik
 
 import (
 	"encoding/json"
+	"fmt"
+	"io"
 	"io/ioutil"
 	"strings"
 	"testing"
@@ -198,3 +200,230 @@ func TestAttachmentsIteratorNext(t *testing.T) {
 		})
 	}
 }
+
+func TestAttachmentsIteratorMetadata(t *testing.T) {
+	iter := &AttachmentsIterator{
+		atti: &mock.Attachments{
+			NextFunc: func(att *driver.Attachment) error {
+				*att = driver.Attachment{
+					Filename:        "foo.txt",
+					ContentType:     "text/plain",
+					Size:            3,
+					ContentEncoding: "gzip",
+					EncodedLength:   23,
+					RevPos:          2,
+					Digest:          "md5-rL0Y20zC+Fzt72VPzMSk2A==",
+				}
+				return nil
+			},
+		},
+	}
+	expected := &Attachment{
+		Filename:        "foo.txt",
+		ContentType:     "text/plain",
+		Size:            3,
+		ContentEncoding: "gzip",
+		EncodedLength:   23,
+		RevPos:          2,
+		Digest:          "md5-rL0Y20zC+Fzt72VPzMSk2A==",
+	}
+	result, err := iter.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := diff.Interface(expected, result); d != nil {
+		t.Error(d)
+	}
+}
+
+type trackingReader struct {
+	io.Reader
+	closed bool
+}
+
+func (r *trackingReader) Close() error {
+	r.closed = true
+	return nil
+}
+
+func TestAttachmentsIteratorSkip(t *testing.T) {
+	var contents []*trackingReader
+	closed := false
+	iter := &AttachmentsIterator{
+		atti: &mock.Attachments{
+			NextFunc: func(att *driver.Attachment) error {
+				if len(contents) == 2 {
+					return io.EOF
+				}
+				// The previous attachment must be consumed before the next
+				// can be read from the stream.
+				if len(contents) > 0 {
+					if prev := contents[len(contents)-1]; !prev.closed {
+						return errors.New("previous attachment not closed")
+					}
+				}
+				r := &trackingReader{Reader: strings.NewReader("content")}
+				contents = append(contents, r)
+				*att = driver.Attachment{Filename: fmt.Sprintf("%d.txt", len(contents)), Content: r}
+				return nil
+			},
+			CloseFunc: func() error {
+				closed = true
+				return nil
+			},
+		},
+	}
+	if err := iter.Skip(); err != nil {
+		t.Errorf("Skip before Next failed: %s", err)
+	}
+	if _, err := iter.Next(); err != nil {
+		t.Fatal(err)
+	}
+	if err := iter.Skip(); err != nil {
+		t.Fatal(err)
+	}
+	att, err := iter.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if att.Filename != "2.txt" {
+		t.Errorf("Unexpected filename: %s", att.Filename)
+	}
+	if _, err := iter.Next(); err != io.EOF {
+		t.Errorf("Expected io.EOF, got %v", err)
+	}
+	if err := iter.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if !closed {
+		t.Errorf("Iterator not closed")
+	}
+	for _, r := range contents {
+		if !r.closed {
+			t.Errorf("Attachment content not closed")
+		}
+		if n, _ := r.Read(make([]byte, 1)); n != 0 {
+			t.Errorf("Attachment content not consumed")
+		}
+	}
+}
+
+// closingReader fails reads once closed, as do files and HTTP bodies.
+type closingReader struct {
+	*trackingReader
+}
+
+func (r *closingReader) Read(p []byte) (int, error) {
+	if r.closed {
+		return 0, errors.New("read after close")
+	}
+	return r.trackingReader.Read(p)
+}
+
+func TestAttachmentsIteratorClosedContent(t *testing.T) {
+	var contents []*closingReader
+	iter := &AttachmentsIterator{
+		atti: &mock.Attachments{
+			NextFunc: func(att *driver.Attachment) error {
+				if len(contents) == 2 {
+					return io.EOF
+				}
+				if len(contents) > 0 {
+					if prev := contents[len(contents)-1]; !prev.closed {
+						return errors.New("previous attachment not closed")
+					}
+				}
+				r := &closingReader{&trackingReader{Reader: strings.NewReader("content")}}
+				contents = append(contents, r)
+				*att = driver.Attachment{Filename: fmt.Sprintf("%d.txt", len(contents)), Content: r}
+				return nil
+			},
+		},
+	}
+	att, err := iter.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := att.Content.Read(make([]byte, 3)); err != nil {
+		t.Fatal(err)
+	}
+	if err := att.Content.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := contents[0].trackingReader.Read(make([]byte, 1)); n != 0 {
+		t.Errorf("Unread content not discarded on close")
+	}
+	if err := att.Content.Close(); err != nil {
+		t.Errorf("Second close failed: %s", err)
+	}
+	att, err = iter.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if att.Filename != "2.txt" {
+		t.Errorf("Unexpected filename: %s", att.Filename)
+	}
+}
+
+func TestMD5sumDigest(t *testing.T) {
+	sum := MD5sum{0xd4, 0x1d, 0x8c, 0xd9, 0x8f, 0x00, 0xb2, 0x04, 0xe9, 0x80, 0x09, 0x98, 0xec, 0xf8, 0x42, 0x7e}
+	expected := "md5-1B2M2Y8AsgTpgAmY7PhCfg=="
+	if digest := sum.Digest(); digest != expected {
+		t.Errorf("Unexpected digest: %s", digest)
+	}
+	parsed, ok := parseMD5Digest(expected)
+	if !ok || parsed != sum {
+		t.Errorf("Failed to parse digest: %v", parsed)
+	}
+	for _, digest := range []string{"", "md5-foo", "sha-1B2M2Y8AsgTpgAmY7PhCfg=="} {
+		if _, ok := parseMD5Digest(digest); ok {
+			t.Errorf("Unexpectedly parsed %q", digest)
+		}
+	}
+}
+
+func TestAttachmentStoredIdentity(t *testing.T) {
+	tests := []struct {
+		name     string
+		att      *Attachment
+		expected bool
+	}{
+		{
+			name:     "no metadata",
+			att:      &Attachment{},
+			expected: true,
+		},
+		{
+			name:     "binary",
+			att:      &Attachment{ContentType: "image/png", Size: 10, EncodedLength: 10},
+			expected: true,
+		},
+		{
+			name:     "gzip",
+			att:      &Attachment{ContentType: "image/png", ContentEncoding: "gzip"},
+			expected: false,
+		},
+		{
+			name:     "encoded length differs",
+			att:      &Attachment{ContentType: "image/png", Size: 10, EncodedLength: 8},
+			expected: false,
+		},
+		{
+			name:     "text",
+			att:      &Attachment{ContentType: "text/html; charset=utf-8"},
+			expected: false,
+		},
+		{
+			name:     "xml",
+			att:      &Attachment{ContentType: "Application/XML"},
+			expected: false,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if result := test.att.storedIdentity(); result != test.expected {
+				t.Errorf("Unexpected result: %t", result)
+			}
+		})
+	}
+}
@@ -0,0 +1,306 @@
+package kivik
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+
+	"github.com/go-kivik/kivik/errors"
+)
+
+// AttachmentSyncOptions modify the behavior of PutAttachmentsFromDir.
+type AttachmentSyncOptions struct {
+	// DeleteRemoved causes attachments with no corresponding file to be
+	// deleted from the document.
+	DeleteRemoved bool
+	// DryRun causes PutAttachmentsFromDir to report the changes it would
+	// make, without making them.
+	DryRun bool
+}
+
+// AttachmentSyncReport describes the changes made by PutAttachmentsFromDir or
+// ExtractAttachments. Attachments are identified by filename.
+type AttachmentSyncReport struct {
+	// Rev is the document's revision after all changes were made.
+	Rev string
+	// Copied lists the attachments which were new or changed, and were
+	// copied.
+	Copied []string
+	// Deleted lists the attachments which were deleted.
+	Deleted []string
+	// Unchanged lists the attachments whose content already matched.
+	Unchanged []string
+}
+
+// PutAttachmentsFromDir mirrors the files in dir, and its subdirectories, to
+// the attachments of the document identified by docID, which is created if it
+// does not exist. Attachment filenames are the paths of the files relative to
+// dir, using forward slashes.
+//
+// Files are compared against the document's attachment stubs by MD5 digest,
+// and only new or changed files are uploaded. As the digest of an attachment
+// which the server stored compressed is that of the compressed data, such
+// attachments are downloaded to compare their content. Content types are
+// inferred from file extensions, or else from file contents. If
+// options.DeleteRemoved is true, attachments with no corresponding file are
+// deleted.
+func (db *DB) PutAttachmentsFromDir(ctx context.Context, docID, dir string, options AttachmentSyncOptions) (*AttachmentSyncReport, error) {
+	if docID == "" {
+		return nil, missingArg("docID")
+	}
+	rev, existing, err := db.attachmentStubs(ctx, docID)
+	if err != nil && StatusCode(err) != StatusNotFound {
+		return nil, err
+	}
+	files, err := readAttachmentDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	report := &AttachmentSyncReport{Rev: rev}
+	for _, filename := range sortedFilenames(files) {
+		if att, ok := existing[filename]; ok {
+			same, err := db.attachmentMatches(ctx, docID, rev, filename, att, files[filename])
+			if err != nil {
+				return nil, err
+			}
+			if same {
+				report.Unchanged = append(report.Unchanged, filename)
+				continue
+			}
+		}
+		report.Copied = append(report.Copied, filename)
+		if options.DryRun {
+			continue
+		}
+		if report.Rev, err = db.putAttachmentFile(ctx, docID, report.Rev, dir, filename); err != nil {
+			return nil, err
+		}
+	}
+	if !options.DeleteRemoved {
+		return report, nil
+	}
+	for _, filename := range existing.filenames() {
+		if _, ok := files[filename]; ok {
+			continue
+		}
+		report.Deleted = append(report.Deleted, filename)
+		if options.DryRun {
+			continue
+		}
+		if report.Rev, err = db.DeleteAttachment(ctx, docID, report.Rev, filename); err != nil {
+			return nil, err
+		}
+	}
+	return report, nil
+}
+
+// ExtractAttachments writes the attachments of the document identified by
+// docID to files in dir, creating subdirectories as needed for filenames
+// containing slashes. Existing files are compared against the attachment
+// stubs by MD5 digest, and only new or changed attachments are downloaded.
+// Attachments which the server stored compressed are always downloaded, but
+// existing files with the same content are left unmodified. Files with no
+// corresponding attachment are left in place.
+func (db *DB) ExtractAttachments(ctx context.Context, docID, dir string) (*AttachmentSyncReport, error) {
+	if docID == "" {
+		return nil, missingArg("docID")
+	}
+	rev, existing, err := db.attachmentStubs(ctx, docID)
+	if err != nil {
+		return nil, err
+	}
+	report := &AttachmentSyncReport{Rev: rev}
+	for _, filename := range existing.filenames() {
+		target, err := attachmentPath(dir, filename)
+		if err != nil {
+			return nil, err
+		}
+		digest, err := fileDigest(target)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, errors.WrapStatus(StatusBadRequest, err)
+		}
+		var current string
+		if err == nil {
+			if stub := existing[filename]; stub.storedIdentity() && digest == stub.Digest {
+				report.Unchanged = append(report.Unchanged, filename)
+				continue
+			}
+			current = digest
+		}
+		changed, err := db.extractAttachment(ctx, docID, rev, filename, target, current)
+		if err != nil {
+			return nil, err
+		}
+		if changed {
+			report.Copied = append(report.Copied, filename)
+		} else {
+			report.Unchanged = append(report.Unchanged, filename)
+		}
+	}
+	return report, nil
+}
+
+// attachmentStubs returns the current revision of the document, and its
+// attachment stubs.
+func (db *DB) attachmentStubs(ctx context.Context, docID string) (string, Attachments, error) {
+	var doc struct {
+		Rev         string      `json:"_rev"`
+		Attachments Attachments `json:"_attachments"`
+	}
+	if err := db.Get(ctx, docID).ScanDoc(&doc); err != nil {
+		return "", nil, err
+	}
+	return doc.Rev, doc.Attachments, nil
+}
+
+func (db *DB) putAttachmentFile(ctx context.Context, docID, rev, dir, filename string) (string, error) {
+	f, err := os.Open(filepath.Join(dir, filepath.FromSlash(filename)))
+	if err != nil {
+		return "", errors.WrapStatus(StatusBadRequest, err)
+	}
+	defer f.Close() // nolint: errcheck
+	contentType, err := detectContentType(f)
+	if err != nil {
+		return "", err
+	}
+	return db.PutAttachment(ctx, docID, rev, &Attachment{
+		Filename:    filename,
+		ContentType: contentType,
+		Content:     f,
+	})
+}
+
+// attachmentMatches returns true if the content of the attachment described
+// by stub has the MD5 digest given. The stub's digest is compared directly if
+// the attachment is stored uncompressed. Otherwise, the stub's digest is that
+// of the compressed data, so the attachment is downloaded and hashed.
+func (db *DB) attachmentMatches(ctx context.Context, docID, rev, filename string, stub *Attachment, digest string) (bool, error) {
+	if stub.storedIdentity() {
+		return stub.Digest == digest, nil
+	}
+	att, err := db.GetAttachment(ctx, docID, rev, filename)
+	if err != nil {
+		return false, err
+	}
+	r := newMD5Reader(att.Content)
+	defer r.Close() // nolint: errcheck
+	if _, err := io.Copy(ioutil.Discard, r); err != nil {
+		return false, err
+	}
+	return r.sum().Digest() == digest, nil
+}
+
+// extractAttachment downloads the attachment to target. If current is not
+// empty, it is the MD5 digest of the existing target file, which is left
+// unmodified if the downloaded content matches. changed is false in that case.
+func (db *DB) extractAttachment(ctx context.Context, docID, rev, filename, target, current string) (changed bool, err error) {
+	att, err := db.GetAttachment(ctx, docID, rev, filename)
+	if err != nil {
+		return false, err
+	}
+	defer att.Content.Close() // nolint: errcheck
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return false, errors.WrapStatus(StatusBadRequest, err)
+	}
+	// Write to a temporary file first, so that a failed download doesn't
+	// leave a partial file in place.
+	tmp, err := ioutil.TempFile(filepath.Dir(target), ".kivik-")
+	if err != nil {
+		return false, errors.WrapStatus(StatusBadRequest, err)
+	}
+	defer os.Remove(tmp.Name()) // nolint: errcheck
+	content := newMD5Reader(att.Content)
+	if _, err := io.Copy(tmp, content); err != nil {
+		_ = tmp.Close()
+		return false, err
+	}
+	if err := tmp.Close(); err != nil {
+		return false, errors.WrapStatus(StatusBadRequest, err)
+	}
+	if current != "" && content.sum().Digest() == current {
+		return false, nil
+	}
+	return true, errors.WrapStatus(StatusBadRequest, os.Rename(tmp.Name(), target))
+}
+
+// readAttachmentDir returns the MD5 digest of each regular file in dir and its
+// subdirectories, keyed by attachment filename.
+func readAttachmentDir(dir string) (map[string]string, error) {
+	files := make(map[string]string)
+	err := filepath.Walk(dir, func(p string, info os.FileInfo, err error) error {
+		if err != nil || !info.Mode().IsRegular() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		digest, err := fileDigest(p)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(rel)] = digest
+		return nil
+	})
+	if err != nil {
+		return nil, errors.WrapStatus(StatusBadRequest, err)
+	}
+	return files, nil
+}
+
+// attachmentPath returns the path within dir to which the named attachment
+// is extracted, or an error if the filename would escape dir.
+func attachmentPath(dir, filename string) (string, error) {
+	clean := path.Clean("/" + filename)
+	if clean == "/" || clean[1:] != filename {
+		return "", errors.Statusf(StatusBadRequest, "kivik: invalid attachment filename for extraction: %s", filename)
+	}
+	return filepath.Join(dir, filepath.FromSlash(filename)), nil
+}
+
+// fileDigest returns the MD5 digest of the file, in the format used by
+// CouchDB.
+func fileDigest(name string) (string, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return "", err
+	}
+	r := newMD5Reader(f)
+	defer r.Close() // nolint: errcheck
+	if _, err := io.Copy(ioutil.Discard, r); err != nil {
+		return "", err
+	}
+	return r.sum().Digest(), nil
+}
+
+// detectContentType infers the content type of f from its extension, or
+// failing that, its first 512 bytes. f is left positioned at its start.
+func detectContentType(f *os.File) (string, error) {
+	if ct := mime.TypeByExtension(filepath.Ext(f.Name())); ct != "" {
+		return ct, nil
+	}
+	buf := make([]byte, 512)
+	n, err := io.ReadFull(f, buf)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", errors.WrapStatus(StatusBadRequest, err)
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return "", errors.WrapStatus(StatusBadRequest, err)
+	}
+	return http.DetectContentType(buf[:n]), nil
+}
+
+func sortedFilenames(files map[string]string) []string {
+	filenames := make([]string, 0, len(files))
+	for filename := range files {
+		filenames = append(filenames, filename)
+	}
+	sort.Strings(filenames)
+	return filenames
+}
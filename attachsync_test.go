@@ -0,0 +1,369 @@
+package kivik
+
+import (
+	"context"
+	"crypto/md5"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/flimzy/diff"
+	"github.com/flimzy/testy"
+	"github.com/go-kivik/kivik/driver"
+	"github.com/go-kivik/kivik/errors"
+	"github.com/go-kivik/kivik/mock"
+)
+
+func testDigest(content string) string {
+	return MD5sum(md5.Sum([]byte(content))).Digest()
+}
+
+// gzipped returns true if attachmentsDB stores the named attachment as
+// gzip-encoded, in which case its digest is not that of its content.
+func gzipped(filename string) bool {
+	return strings.HasSuffix(filename, ".json")
+}
+
+// attachmentsDB returns a mock DB containing a single document, foo, with the
+// provided attachment content. Writes are recorded in ops.
+func attachmentsDB(atts map[string]string, ops *[]string) *mock.DB {
+	rev := 1
+	return &mock.DB{
+		GetFunc: func(_ context.Context, docID string, _ map[string]interface{}) (*driver.Document, error) {
+			if atts == nil {
+				return nil, errors.Status(StatusNotFound, "missing")
+			}
+			stubs := make([]string, 0, len(atts))
+			for filename, content := range atts {
+				if gzipped(filename) {
+					stubs = append(stubs, fmt.Sprintf(`%q:{"stub":true,"content_type":"application/json","encoding":"gzip","length":%d,"encoded_length":%d,"digest":%q}`,
+						filename, len(content), len(content)+20, testDigest("gzip "+content)))
+					continue
+				}
+				stubs = append(stubs, fmt.Sprintf(`%q:{"stub":true,"digest":%q}`, filename, testDigest(content)))
+			}
+			return &driver.Document{
+				Body: body(fmt.Sprintf(`{"_id":%q,"_rev":"%d-x","_attachments":{%s}}`, docID, rev, strings.Join(stubs, ","))),
+			}, nil
+		},
+		PutAttachmentFunc: func(_ context.Context, _, rev string, att *driver.Attachment, _ map[string]interface{}) (string, error) {
+			content, err := ioutil.ReadAll(att.Content)
+			if err != nil {
+				return "", err
+			}
+			*ops = append(*ops, fmt.Sprintf("put %s %s %s %s", rev, att.Filename, att.ContentType, content))
+			return fmt.Sprintf("%d-x", len(*ops)+1), nil
+		},
+		DeleteAttachmentFunc: func(_ context.Context, _, rev, filename string, _ map[string]interface{}) (string, error) {
+			*ops = append(*ops, fmt.Sprintf("delete %s %s", rev, filename))
+			return fmt.Sprintf("%d-x", len(*ops)+1), nil
+		},
+		GetAttachmentFunc: func(_ context.Context, _, rev, filename string, _ map[string]interface{}) (*driver.Attachment, error) {
+			*ops = append(*ops, fmt.Sprintf("get %s %s", rev, filename))
+			if gzipped(filename) {
+				return &driver.Attachment{
+					Filename:        filename,
+					ContentType:     "application/json",
+					ContentEncoding: "gzip",
+					Size:            int64(len(atts[filename])),
+					EncodedLength:   int64(len(atts[filename]) + 20),
+					Digest:          testDigest("gzip " + atts[filename]),
+					Content:         body(atts[filename]),
+				}, nil
+			}
+			return &driver.Attachment{
+				Filename: filename,
+				Digest:   testDigest(atts[filename]),
+				Content:  body(atts[filename]),
+			}, nil
+		},
+	}
+}
+
+func TestPutAttachmentsFromDir(t *testing.T) {
+	existing := map[string]string{
+		"same.html":    "same",
+		"changed.html": "old",
+		"removed.html": "removed",
+	}
+	files := map[string]string{
+		"same.html":    "same",
+		"changed.html": "new",
+		"sub/README":   "hello",
+	}
+	tests := []struct {
+		name     string
+		db       driver.DB
+		existing map[string]string
+		docID    string
+		options  AttachmentSyncOptions
+		files    map[string]string
+		expected *AttachmentSyncReport
+		ops      []string
+		status   int
+		err      string
+	}{
+		{
+			name:   "no docID",
+			status: StatusBadRequest,
+			err:    "kivik: docID required",
+		},
+		{
+			name: "get error",
+			db: &mock.DB{
+				GetFunc: func(_ context.Context, _ string, _ map[string]interface{}) (*driver.Document, error) {
+					return nil, errors.Status(StatusForbidden, "get error")
+				},
+			},
+			docID:  "foo",
+			status: StatusForbidden,
+			err:    "get error",
+		},
+		{
+			name:  "new document",
+			docID: "foo",
+			expected: &AttachmentSyncReport{
+				Rev:    "4-x",
+				Copied: []string{"changed.html", "same.html", "sub/README"},
+			},
+			ops: []string{
+				"put  changed.html text/html; charset=utf-8 new",
+				"put 2-x same.html text/html; charset=utf-8 same",
+				"put 3-x sub/README text/plain; charset=utf-8 hello",
+			},
+		},
+		{
+			name:     "keep removed",
+			existing: existing,
+			docID:    "foo",
+			expected: &AttachmentSyncReport{
+				Rev:       "3-x",
+				Copied:    []string{"changed.html", "sub/README"},
+				Unchanged: []string{"same.html"},
+			},
+			ops: []string{
+				"put 1-x changed.html text/html; charset=utf-8 new",
+				"put 2-x sub/README text/plain; charset=utf-8 hello",
+			},
+		},
+		{
+			name:     "delete removed",
+			existing: existing,
+			docID:    "foo",
+			options:  AttachmentSyncOptions{DeleteRemoved: true},
+			expected: &AttachmentSyncReport{
+				Rev:       "4-x",
+				Copied:    []string{"changed.html", "sub/README"},
+				Deleted:   []string{"removed.html"},
+				Unchanged: []string{"same.html"},
+			},
+			ops: []string{
+				"put 1-x changed.html text/html; charset=utf-8 new",
+				"put 2-x sub/README text/plain; charset=utf-8 hello",
+				"delete 3-x removed.html",
+			},
+		},
+		{
+			name: "gzip encoded stubs",
+			existing: map[string]string{
+				"same.json":    "same",
+				"changed.json": "old",
+			},
+			docID: "foo",
+			expected: &AttachmentSyncReport{
+				Rev:       "3-x",
+				Copied:    []string{"changed.json"},
+				Unchanged: []string{"same.json"},
+			},
+			files: map[string]string{
+				"same.json":    "same",
+				"changed.json": "new",
+			},
+			ops: []string{
+				"get 1-x changed.json",
+				"put 1-x changed.json application/json new",
+				"get 1-x same.json",
+			},
+		},
+		{
+			name:     "dry run",
+			existing: existing,
+			docID:    "foo",
+			options:  AttachmentSyncOptions{DeleteRemoved: true, DryRun: true},
+			expected: &AttachmentSyncReport{
+				Rev:       "1-x",
+				Copied:    []string{"changed.html", "sub/README"},
+				Deleted:   []string{"removed.html"},
+				Unchanged: []string{"same.html"},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "kivik-atts-")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir) // nolint: errcheck
+			if test.files != nil {
+				writeFiles(t, dir, test.files)
+			} else {
+				writeFiles(t, dir, files)
+			}
+			var ops []string
+			dbi := test.db
+			if dbi == nil {
+				dbi = attachmentsDB(test.existing, &ops)
+			}
+			db := &DB{driverDB: dbi}
+			result, err := db.PutAttachmentsFromDir(context.Background(), test.docID, dir, test.options)
+			testy.StatusError(t, test.err, test.status, err)
+			if d := diff.Interface(test.expected, result); d != nil {
+				t.Error(d)
+			}
+			if d := diff.Interface(test.ops, ops); d != nil {
+				t.Errorf("Unexpected operations:\n%s", d)
+			}
+		})
+	}
+	t.Run("missing dir", func(t *testing.T) {
+		var ops []string
+		db := &DB{driverDB: attachmentsDB(nil, &ops)}
+		_, err := db.PutAttachmentsFromDir(context.Background(), "foo", "/this/does/not/exist", AttachmentSyncOptions{})
+		if StatusCode(err) != StatusBadRequest {
+			t.Errorf("Unexpected error: %s", err)
+		}
+	})
+}
+
+func TestExtractAttachments(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing map[string]string
+		docID    string
+		files    map[string]string
+		expected *AttachmentSyncReport
+		ops      []string
+		result   map[string]string
+		status   int
+		err      string
+	}{
+		{
+			name:   "no docID",
+			status: StatusBadRequest,
+			err:    "kivik: docID required",
+		},
+		{
+			name:   "not found",
+			docID:  "foo",
+			status: StatusNotFound,
+			err:    "missing",
+		},
+		{
+			name:     "invalid filename",
+			existing: map[string]string{"../evil": "x"},
+			docID:    "foo",
+			status:   StatusBadRequest,
+			err:      "kivik: invalid attachment filename for extraction: ../evil",
+		},
+		{
+			name: "success",
+			existing: map[string]string{
+				"a.txt":     "a",
+				"b.txt":     "b",
+				"sub/c.txt": "c",
+			},
+			docID: "foo",
+			files: map[string]string{
+				"a.txt":     "a",
+				"b.txt":     "old",
+				"other.txt": "other",
+			},
+			expected: &AttachmentSyncReport{
+				Rev:       "1-x",
+				Copied:    []string{"b.txt", "sub/c.txt"},
+				Unchanged: []string{"a.txt"},
+			},
+			ops: []string{
+				"get 1-x b.txt",
+				"get 1-x sub/c.txt",
+			},
+			result: map[string]string{
+				"a.txt":     "a",
+				"b.txt":     "b",
+				"other.txt": "other",
+				"sub/c.txt": "c",
+			},
+		},
+		{
+			name: "gzip encoded stubs",
+			existing: map[string]string{
+				"same.json":    `{"a":1}`,
+				"changed.json": `{"b":2}`,
+				"new.json":     `{"c":3}`,
+			},
+			docID: "foo",
+			files: map[string]string{
+				"same.json":    `{"a":1}`,
+				"changed.json": `{"b":1}`,
+			},
+			expected: &AttachmentSyncReport{
+				Rev:       "1-x",
+				Copied:    []string{"changed.json", "new.json"},
+				Unchanged: []string{"same.json"},
+			},
+			ops: []string{
+				"get 1-x changed.json",
+				"get 1-x new.json",
+				"get 1-x same.json",
+			},
+			result: map[string]string{
+				"same.json":    `{"a":1}`,
+				"changed.json": `{"b":2}`,
+				"new.json":     `{"c":3}`,
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "kivik-atts-")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir) // nolint: errcheck
+			writeFiles(t, dir, test.files)
+			var ops []string
+			db := &DB{driverDB: attachmentsDB(test.existing, &ops)}
+			result, err := db.ExtractAttachments(context.Background(), test.docID, dir)
+			testy.StatusError(t, test.err, test.status, err)
+			if d := diff.Interface(test.expected, result); d != nil {
+				t.Error(d)
+			}
+			if d := diff.Interface(test.ops, ops); d != nil {
+				t.Errorf("Unexpected operations:\n%s", d)
+			}
+			files := make(map[string]string)
+			err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+				if err != nil || info.IsDir() {
+					return err
+				}
+				content, err := ioutil.ReadFile(path)
+				if err != nil {
+					return err
+				}
+				rel, _ := filepath.Rel(dir, path)
+				files[filepath.ToSlash(rel)] = string(content)
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if d := diff.Interface(test.result, files); d != nil {
+				t.Errorf("Unexpected files:\n%s", d)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package kivik
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/hex"
+	"hash"
+
+	"github.com/go-kivik/kivik/driver"
+)
+
+// BasicAuth is an authenticator for HTTP Basic authentication, for use with
+// Client.Authenticate. The credentials are sent with every request.
+type BasicAuth struct {
+	Username string
+	Password string
+}
+
+// CookieAuth is an authenticator for CouchDB cookie authentication, for use
+// with Client.Authenticate. The credentials are exchanged for a session
+// cookie, which is sent with subsequent requests.
+//
+// See http://docs.couchdb.org/en/2.1.1/api/server/authn.html#cookie-authentication
+type CookieAuth struct {
+	Username string
+	Password string
+}
+
+// ProxyAuth is an authenticator for CouchDB proxy authentication, for use
+// with Client.Authenticate, where a trusted proxy asserts the identity and
+// roles of the user.
+//
+// If the server requires signed requests, set either Token to the
+// pre-computed token, or Secret to the server's secret, in which case the
+// token is computed on the client, as with ProxyAuthToken.
+//
+// See http://docs.couchdb.org/en/2.1.1/api/server/authn.html#proxy-authentication
+type ProxyAuth struct {
+	Username string
+	Roles    []string
+	// Token is the hex-encoded HMAC of Username, signed with the server's
+	// secret.
+	Token string
+	// Secret is the server's proxy authentication secret, used to compute
+	// Token if it is empty. Secret is never sent to the server.
+	Secret string
+	// Hash returns the hash used to compute Token. If nil, SHA-1 is used,
+	// as expected by CouchDB 2.x.
+	Hash func() hash.Hash
+}
+
+// JWTAuth is an authenticator for JSON Web Token authentication, for use with
+// Client.Authenticate. The token is sent as a bearer token with every
+// request.
+type JWTAuth struct {
+	Token string
+}
+
+// ProxyAuthToken returns the token expected by CouchDB for proxy
+// authentication of username: the hex-encoded HMAC-SHA1 of username, signed
+// with secret.
+func ProxyAuthToken(secret, username string) string {
+	return proxyAuthToken(sha1.New, secret, username)
+}
+
+func proxyAuthToken(h func() hash.Hash, secret, username string) string {
+	mac := hmac.New(h, []byte(secret))
+	_, _ = mac.Write([]byte(username))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// driverAuthenticator converts Kivik authenticator types to their driver
+// equivalents. Any other value is returned unaltered.
+func driverAuthenticator(a interface{}) interface{} {
+	switch t := a.(type) {
+	case BasicAuth:
+		return &driver.BasicAuth{Username: t.Username, Password: t.Password}
+	case *BasicAuth:
+		return driverAuthenticator(*t)
+	case CookieAuth:
+		return &driver.CookieAuth{Username: t.Username, Password: t.Password}
+	case *CookieAuth:
+		return driverAuthenticator(*t)
+	case ProxyAuth:
+		token := t.Token
+		if token == "" && t.Secret != "" {
+			h := t.Hash
+			if h == nil {
+				h = sha1.New
+			}
+			token = proxyAuthToken(h, t.Secret, t.Username)
+		}
+		return &driver.ProxyAuth{Username: t.Username, Roles: t.Roles, Token: token}
+	case *ProxyAuth:
+		return driverAuthenticator(*t)
+	case JWTAuth:
+		return &driver.JWTAuth{Token: t.Token}
+	case *JWTAuth:
+		return driverAuthenticator(*t)
+	}
+	return a
+}
@@ -0,0 +1,92 @@
+package kivik
+
+import (
+	"context"
+	"crypto/sha256"
+	"testing"
+
+	"github.com/flimzy/diff"
+	"github.com/go-kivik/kivik/driver"
+	"github.com/go-kivik/kivik/mock"
+)
+
+func TestProxyAuthToken(t *testing.T) {
+	expected := "dcd244bed8f9dffffa806d4c9523d744d236df13"
+	if token := ProxyAuthToken("secret", "bob"); token != expected {
+		t.Errorf("Unexpected token: %s", token)
+	}
+}
+
+func TestDriverAuthenticator(t *testing.T) {
+	type custom struct{ foo string }
+	tests := []struct {
+		name     string
+		auth     interface{}
+		expected interface{}
+	}{
+		{
+			name:     "basic",
+			auth:     BasicAuth{Username: "bob", Password: "abc123"},
+			expected: &driver.BasicAuth{Username: "bob", Password: "abc123"},
+		},
+		{
+			name:     "basic pointer",
+			auth:     &BasicAuth{Username: "bob", Password: "abc123"},
+			expected: &driver.BasicAuth{Username: "bob", Password: "abc123"},
+		},
+		{
+			name:     "cookie",
+			auth:     &CookieAuth{Username: "bob", Password: "abc123"},
+			expected: &driver.CookieAuth{Username: "bob", Password: "abc123"},
+		},
+		{
+			name:     "proxy, unsigned",
+			auth:     ProxyAuth{Username: "bob", Roles: []string{"staff", "ops"}},
+			expected: &driver.ProxyAuth{Username: "bob", Roles: []string{"staff", "ops"}},
+		},
+		{
+			name:     "proxy, token",
+			auth:     &ProxyAuth{Username: "bob", Token: "abc", Secret: "ignored"},
+			expected: &driver.ProxyAuth{Username: "bob", Token: "abc"},
+		},
+		{
+			name:     "proxy, secret",
+			auth:     &ProxyAuth{Username: "bob", Secret: "secret"},
+			expected: &driver.ProxyAuth{Username: "bob", Token: "dcd244bed8f9dffffa806d4c9523d744d236df13"},
+		},
+		{
+			name:     "proxy, custom hash",
+			auth:     &ProxyAuth{Username: "bob", Secret: "secret", Hash: sha256.New},
+			expected: &driver.ProxyAuth{Username: "bob", Token: "9c90819f883772660da011f41042fabea4a174e2873386b30949f106dbac797e"},
+		},
+		{
+			name:     "jwt",
+			auth:     JWTAuth{Token: "xxx.yyy.zzz"},
+			expected: &driver.JWTAuth{Token: "xxx.yyy.zzz"},
+		},
+		{
+			name:     "driver-specific",
+			auth:     custom{foo: "bar"},
+			expected: custom{foo: "bar"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var result interface{}
+			client := &Client{
+				driverClient: &mock.Authenticator{
+					AuthenticateFunc: func(_ context.Context, a interface{}) error {
+						result = a
+						return nil
+					},
+				},
+			}
+			if err := client.Authenticate(context.Background(), test.auth); err != nil {
+				t.Fatal(err)
+			}
+			if d := diff.Interface(test.expected, result); d != nil {
+				t.Error(d)
+			}
+		})
+	}
+}
@@ -82,6 +82,9 @@ func (db *DB) Changes(ctx context.Context, options ...Options) (*Changes, error)
 		return nil, err
 	}
 	changesi, err := db.driverDB.Changes(ctx, opts)
+	if db.client.renewSession(ctx, err) {
+		changesi, err = db.driverDB.Changes(ctx, opts)
+	}
 	if err != nil {
 		return nil, err
 	}
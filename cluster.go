@@ -0,0 +1,119 @@
+package kivik
+
+import (
+	"context"
+
+	"github.com/go-kivik/kivik/driver"
+	"github.com/go-kivik/kivik/errors"
+)
+
+// ClusterMembership lists the nodes of a cluster.
+type ClusterMembership struct {
+	// AllNodes lists all the nodes this node knows about, including those
+	// which are not part of the cluster.
+	AllNodes []string `json:"all_nodes"`
+	// ClusterNodes lists the nodes which are members of the cluster.
+	ClusterNodes []string `json:"cluster_nodes"`
+}
+
+// Cluster setup actions, for use in ClusterSetupAction.
+const (
+	ClusterActionEnableSingleNode = "enable_single_node"
+	ClusterActionEnableCluster    = "enable_cluster"
+	ClusterActionAddNode          = "add_node"
+	ClusterActionFinishCluster    = "finish_cluster"
+)
+
+// Cluster setup states, as returned by ClusterSetupStatus.
+const (
+	ClusterStateDisabled           = "cluster_disabled"
+	ClusterStateSingleNodeDisabled = "single_node_disabled"
+	ClusterStateSingleNodeEnabled  = "single_node_enabled"
+	ClusterStateEnabled            = "cluster_enabled"
+	ClusterStateFinished           = "cluster_finished"
+)
+
+// ClusterSetupAction is a request to the cluster setup endpoint. Which fields
+// are required depends on the Action.
+//
+// See http://docs.couchdb.org/en/2.1.1/cluster/setup.html#the-cluster-setup-api
+type ClusterSetupAction struct {
+	// Action is one of the ClusterAction constants.
+	Action string `json:"action"`
+	// Username and Password are the credentials of the server admin to
+	// create.
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	// BindAddress is the address the node should listen on, such as
+	// "0.0.0.0".
+	BindAddress string `json:"bind_address,omitempty"`
+	// Port is the port the node should listen on.
+	Port int `json:"port,omitempty"`
+	// NodeCount is the expected number of nodes in the cluster.
+	NodeCount int `json:"node_count,omitempty"`
+	// RemoteNode is the address of a remote node to enable, along with the
+	// credentials of an existing admin on that node.
+	RemoteNode            string `json:"remote_node,omitempty"`
+	RemoteCurrentUser     string `json:"remote_current_user,omitempty"`
+	RemoteCurrentPassword string `json:"remote_current_password,omitempty"`
+	// Host is the address of the node to add to the cluster, for the
+	// add_node action.
+	Host string `json:"host,omitempty"`
+	// EnsureDBsExist lists the system databases to create when finishing the
+	// cluster setup. If empty, the server's defaults are used.
+	EnsureDBsExist []string `json:"ensure_dbs_exist,omitempty"`
+}
+
+func (c *Client) cluster() (driver.Cluster, error) {
+	if cluster, ok := c.driverClient.(driver.Cluster); ok {
+		return cluster, nil
+	}
+	return nil, errors.Status(StatusNotImplemented, "kivik: driver does not support cluster operations")
+}
+
+// Membership returns the list of nodes known to the node handling the
+// request, and those which are part of the cluster.
+//
+// See http://docs.couchdb.org/en/2.1.1/api/server/common.html#membership
+func (c *Client) Membership(ctx context.Context) (*ClusterMembership, error) {
+	cluster, err := c.cluster()
+	if err != nil {
+		return nil, err
+	}
+	m, err := cluster.Membership(ctx)
+	if err != nil {
+		return nil, err
+	}
+	membership := ClusterMembership(*m)
+	return &membership, nil
+}
+
+// ClusterSetupStatus returns the current cluster setup state, which is one of
+// the ClusterState constants.
+//
+// See http://docs.couchdb.org/en/2.1.1/api/server/common.html#get--_cluster_setup
+func (c *Client) ClusterSetupStatus(ctx context.Context, options ...Options) (string, error) {
+	cluster, err := c.cluster()
+	if err != nil {
+		return "", err
+	}
+	opts, err := mergeOptions(options...)
+	if err != nil {
+		return "", err
+	}
+	return cluster.ClusterStatus(ctx, opts)
+}
+
+// ClusterSetup performs the requested cluster setup action.
+//
+// See http://docs.couchdb.org/en/2.1.1/api/server/common.html#post--_cluster_setup
+func (c *Client) ClusterSetup(ctx context.Context, action ClusterSetupAction) error {
+	if action.Action == "" {
+		return missingArg("action")
+	}
+	cluster, err := c.cluster()
+	if err != nil {
+		return err
+	}
+	return cluster.ClusterSetup(ctx, action)
+}
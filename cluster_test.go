@@ -0,0 +1,169 @@
+package kivik
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/flimzy/diff"
+	"github.com/flimzy/testy"
+	"github.com/go-kivik/kivik/driver"
+	"github.com/go-kivik/kivik/errors"
+	"github.com/go-kivik/kivik/mock"
+)
+
+func TestMembership(t *testing.T) {
+	tests := []struct {
+		name     string
+		client   driver.Client
+		expected *ClusterMembership
+		status   int
+		err      string
+	}{
+		{
+			name:   "not supported",
+			client: &mock.Client{},
+			status: StatusNotImplemented,
+			err:    "kivik: driver does not support cluster operations",
+		},
+		{
+			name: "error",
+			client: &mock.Cluster{
+				MembershipFunc: func(_ context.Context) (*driver.ClusterMembership, error) {
+					return nil, errors.Status(StatusForbidden, "membership error")
+				},
+			},
+			status: StatusForbidden,
+			err:    "membership error",
+		},
+		{
+			name: "success",
+			client: &mock.Cluster{
+				MembershipFunc: func(_ context.Context) (*driver.ClusterMembership, error) {
+					return &driver.ClusterMembership{
+						AllNodes:     []string{"node1@127.0.0.1", "node2@127.0.0.1"},
+						ClusterNodes: []string{"node1@127.0.0.1"},
+					}, nil
+				},
+			},
+			expected: &ClusterMembership{
+				AllNodes:     []string{"node1@127.0.0.1", "node2@127.0.0.1"},
+				ClusterNodes: []string{"node1@127.0.0.1"},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := &Client{driverClient: test.client}
+			result, err := client.Membership(context.Background())
+			testy.StatusError(t, test.err, test.status, err)
+			if d := diff.Interface(test.expected, result); d != nil {
+				t.Error(d)
+			}
+		})
+	}
+}
+
+func TestClusterSetupStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		client   driver.Client
+		options  Options
+		expected string
+		status   int
+		err      string
+	}{
+		{
+			name:   "not supported",
+			client: &mock.Client{},
+			status: StatusNotImplemented,
+			err:    "kivik: driver does not support cluster operations",
+		},
+		{
+			name: "success",
+			client: &mock.Cluster{
+				ClusterStatusFunc: func(_ context.Context, opts map[string]interface{}) (string, error) {
+					if d := diff.Interface(testOptions, opts); d != nil {
+						return "", fmt.Errorf("Unexpected options:\n%s", d)
+					}
+					return ClusterStateFinished, nil
+				},
+			},
+			options:  testOptions,
+			expected: ClusterStateFinished,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := &Client{driverClient: test.client}
+			result, err := client.ClusterSetupStatus(context.Background(), test.options)
+			testy.StatusError(t, test.err, test.status, err)
+			if result != test.expected {
+				t.Errorf("Unexpected result: %s", result)
+			}
+		})
+	}
+}
+
+func TestClusterSetup(t *testing.T) {
+	tests := []struct {
+		name   string
+		client driver.Client
+		action ClusterSetupAction
+		status int
+		err    string
+	}{
+		{
+			name:   "missing action",
+			client: &mock.Cluster{},
+			status: StatusBadRequest,
+			err:    "kivik: action required",
+		},
+		{
+			name:   "not supported",
+			client: &mock.Client{},
+			action: ClusterSetupAction{Action: ClusterActionFinishCluster},
+			status: StatusNotImplemented,
+			err:    "kivik: driver does not support cluster operations",
+		},
+		{
+			name: "error",
+			client: &mock.Cluster{
+				ClusterSetupFunc: func(_ context.Context, _ interface{}) error {
+					return errors.Status(StatusBadRequest, "setup error")
+				},
+			},
+			action: ClusterSetupAction{Action: ClusterActionFinishCluster},
+			status: StatusBadRequest,
+			err:    "setup error",
+		},
+		{
+			name: "success",
+			client: &mock.Cluster{
+				ClusterSetupFunc: func(_ context.Context, action interface{}) error {
+					expected := ClusterSetupAction{
+						Action:   ClusterActionEnableCluster,
+						Username: "admin",
+						Password: "abc123",
+					}
+					if d := diff.AsJSON(expected, action); d != nil {
+						return fmt.Errorf("Unexpected action:\n%s", d)
+					}
+					return nil
+				},
+			},
+			action: ClusterSetupAction{
+				Action:   ClusterActionEnableCluster,
+				Username: "admin",
+				Password: "abc123",
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := &Client{driverClient: test.client}
+			err := client.ClusterSetup(context.Background(), test.action)
+			testy.StatusError(t, test.err, test.status, err)
+		})
+	}
+}
@@ -0,0 +1,235 @@
+package kivik
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultCompactPollInterval is the interval at which CompactAndWait checks
+// for completion, if no other interval is given.
+const DefaultCompactPollInterval = time.Second
+
+// CompactAndWait begins compaction of the database, and blocks until it
+// completes, or until ctx is cancelled. Completion is detected by polling
+// Stats for CompactRunning every pollInterval and, if the driver supports it,
+// by checking ActiveTasks for a database_compaction task on this database.
+// As ActiveTasks requires server admin privileges, if it fails with
+// StatusUnauthorized or StatusForbidden, only Stats is used.
+func (db *DB) CompactAndWait(ctx context.Context, pollInterval time.Duration) error {
+	if pollInterval <= 0 {
+		pollInterval = DefaultCompactPollInterval
+	}
+	if err := db.Compact(ctx); err != nil {
+		return err
+	}
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	checkTasks := db.client != nil
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+		running, err := db.compactionRunning(ctx, &checkTasks)
+		if err != nil {
+			return err
+		}
+		if !running {
+			return nil
+		}
+	}
+}
+
+// compactionRunning reports whether compaction of the database is running.
+// Active tasks are checked only if *checkTasks is true, and it is set to false
+// if they are unavailable to the client, so that they are not requested again.
+func (db *DB) compactionRunning(ctx context.Context, checkTasks *bool) (bool, error) {
+	stats, err := db.Stats(ctx)
+	if err != nil {
+		return false, err
+	}
+	if stats.CompactRunning || !*checkTasks {
+		return stats.CompactRunning, nil
+	}
+	tasks, err := db.client.ActiveTasks(ctx)
+	switch StatusCode(err) {
+	case StatusNotImplemented, StatusUnauthorized, StatusForbidden:
+		*checkTasks = false
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	for _, task := range tasks {
+		if task.Type == "database_compaction" && taskDBName(task.Database) == db.name {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// taskDBName returns the name of the database to which an active task's
+// database field refers. CouchDB 2.x and later report the path of a shard,
+// such as shards/00000000-1fffffff/foo.1549387711, rather than the database
+// name. As database names may not contain periods, the shard suffix is
+// everything from the first period.
+func taskDBName(name string) string {
+	if !strings.HasPrefix(name, "shards/") {
+		return name
+	}
+	name = strings.TrimPrefix(name, "shards/")
+	if i := strings.Index(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	if i := strings.Index(name, "."); i >= 0 {
+		name = name[:i]
+	}
+	return name
+}
+
+// CompactPolicy configures CompactAll.
+type CompactPolicy struct {
+	// DBs lists the databases to consider. If empty, all databases are
+	// considered.
+	DBs []string
+	// Threshold is the ratio of DiskSize to ActiveSize above which a database
+	// is compacted. If zero, any database with unused disk space is
+	// compacted.
+	Threshold float64
+	// MinDiskSize is the size, in bytes, below which a database is never
+	// compacted.
+	MinDiskSize int64
+	// Concurrency is the maximum number of databases compacted at once. If
+	// zero, databases are compacted one at a time.
+	Concurrency int
+	// PollInterval is passed to CompactAndWait.
+	PollInterval time.Duration
+}
+
+// CompactResult reports the compaction of a single database.
+type CompactResult struct {
+	// DBName is the name of the compacted database.
+	DBName string
+	// DiskSizeBefore and DiskSizeAfter are the on-disk sizes of the database
+	// before and after compaction.
+	DiskSizeBefore int64
+	DiskSizeAfter  int64
+}
+
+// Reclaimed returns the number of bytes freed by compaction.
+func (r *CompactResult) Reclaimed() int64 {
+	return r.DiskSizeBefore - r.DiskSizeAfter
+}
+
+// CompactReport summarizes the result of CompactAll.
+type CompactReport struct {
+	// Compacted lists the databases which were compacted.
+	Compacted []*CompactResult
+	// Skipped lists the databases which did not meet the policy.
+	Skipped []string
+	// Reclaimed is the total number of bytes freed by compaction.
+	Reclaimed int64
+}
+
+// needsCompaction returns true if stats meet the policy.
+func (p *CompactPolicy) needsCompaction(stats *DBStats) bool {
+	if stats == nil || stats.ActiveSize <= 0 || stats.DiskSize < p.MinDiskSize {
+		return false
+	}
+	ratio := float64(stats.DiskSize) / float64(stats.ActiveSize)
+	if p.Threshold > 0 {
+		return ratio > p.Threshold
+	}
+	return ratio > 1
+}
+
+// CompactAll compacts each database whose ratio of disk size to active size
+// exceeds the policy threshold, waiting for each compaction to complete. On
+// error, no further compactions are started, and the report of those already
+// completed is returned along with the error.
+func (c *Client) CompactAll(ctx context.Context, policy CompactPolicy) (*CompactReport, error) {
+	dbNames := policy.DBs
+	if len(dbNames) == 0 {
+		var err error
+		if dbNames, err = c.AllDBs(ctx); err != nil {
+			return nil, err
+		}
+	}
+	stats, err := c.DBsStats(ctx, dbNames)
+	if err != nil {
+		return nil, err
+	}
+	report := &CompactReport{}
+	var candidates []*CompactResult
+	for i, dbName := range dbNames {
+		if !policy.needsCompaction(stats[i]) {
+			report.Skipped = append(report.Skipped, dbName)
+			continue
+		}
+		candidates = append(candidates, &CompactResult{DBName: dbName, DiskSizeBefore: stats[i].DiskSize})
+	}
+	concurrency := policy.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+		done     = make([]bool, len(candidates))
+	)
+	sem := make(chan struct{}, concurrency)
+	for i, result := range candidates {
+		sem <- struct{}{}
+		mu.Lock()
+		failed := firstErr != nil
+		mu.Unlock()
+		if failed {
+			<-sem
+			break
+		}
+		wg.Add(1)
+		go func(i int, result *CompactResult) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			after, err := c.compactDB(ctx, result.DBName, policy.PollInterval)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+				}
+				return
+			}
+			result.DiskSizeAfter = after
+			done[i] = true
+		}(i, result)
+	}
+	wg.Wait()
+	for i, result := range candidates {
+		if done[i] {
+			report.Compacted = append(report.Compacted, result)
+			report.Reclaimed += result.Reclaimed()
+		}
+	}
+	return report, firstErr
+}
+
+// compactDB compacts dbName, and returns its disk size once complete.
+func (c *Client) compactDB(ctx context.Context, dbName string, pollInterval time.Duration) (int64, error) {
+	db, err := c.DB(ctx, dbName)
+	if err != nil {
+		return 0, err
+	}
+	if err := db.CompactAndWait(ctx, pollInterval); err != nil {
+		return 0, err
+	}
+	stats, err := db.Stats(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return stats.DiskSize, nil
+}
@@ -0,0 +1,227 @@
+package kivik
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/flimzy/diff"
+	"github.com/flimzy/testy"
+	"github.com/go-kivik/kivik/driver"
+	"github.com/go-kivik/kivik/errors"
+	"github.com/go-kivik/kivik/mock"
+)
+
+// compactingDB returns a mock DB which reports compaction running for polls
+// calls to Stats after Compact is called, and then reports a disk size of
+// active.
+func compactingDB(dbName string, disk, active int64, polls int) *mock.DB {
+	var compacting bool
+	var remaining int
+	return &mock.DB{
+		CompactFunc: func(_ context.Context) error {
+			compacting = true
+			remaining = polls
+			return nil
+		},
+		StatsFunc: func(_ context.Context) (*driver.DBStats, error) {
+			if compacting && remaining > 0 {
+				remaining--
+				return &driver.DBStats{Name: dbName, CompactRunning: true, DiskSize: disk, ActiveSize: active}, nil
+			}
+			if compacting {
+				return &driver.DBStats{Name: dbName, DiskSize: active, ActiveSize: active}, nil
+			}
+			return &driver.DBStats{Name: dbName, DiskSize: disk, ActiveSize: active}, nil
+		},
+	}
+}
+
+func TestCompactAndWait(t *testing.T) {
+	t.Run("compact error", func(t *testing.T) {
+		db := &DB{driverDB: &mock.DB{
+			CompactFunc: func(_ context.Context) error {
+				return errors.Status(StatusUnauthorized, "compact error")
+			},
+		}}
+		err := db.CompactAndWait(context.Background(), time.Millisecond)
+		testy.StatusError(t, "compact error", StatusUnauthorized, err)
+	})
+	t.Run("stats error", func(t *testing.T) {
+		db := &DB{driverDB: &mock.DB{
+			CompactFunc: func(_ context.Context) error { return nil },
+			StatsFunc: func(_ context.Context) (*driver.DBStats, error) {
+				return nil, errors.Status(StatusNotFound, "stats error")
+			},
+		}}
+		err := db.CompactAndWait(context.Background(), time.Millisecond)
+		testy.StatusError(t, "stats error", StatusNotFound, err)
+	})
+	t.Run("waits for completion", func(t *testing.T) {
+		driverDB := compactingDB("foo", 100, 10, 3)
+		db := &DB{driverDB: driverDB}
+		if err := db.CompactAndWait(context.Background(), time.Millisecond); err != nil {
+			t.Fatal(err)
+		}
+		stats, _ := db.Stats(context.Background())
+		if stats.CompactRunning {
+			t.Errorf("Returned before compaction completed")
+		}
+	})
+	t.Run("waits for active task", func(t *testing.T) {
+		var taskPolls int
+		client := &Client{driverClient: &mock.ActiveTasker{
+			ActiveTasksFunc: func(_ context.Context) ([]*driver.ActiveTask, error) {
+				taskPolls++
+				if taskPolls < 3 {
+					return []*driver.ActiveTask{
+						{Type: "database_compaction", Database: "bar"},
+						{Type: "database_compaction", Database: "foo"},
+					}, nil
+				}
+				return []*driver.ActiveTask{{Type: "indexer", Database: "foo"}}, nil
+			},
+		}}
+		db := &DB{client: client, name: "foo", driverDB: compactingDB("foo", 100, 10, 0)}
+		if err := db.CompactAndWait(context.Background(), time.Millisecond); err != nil {
+			t.Fatal(err)
+		}
+		if taskPolls != 3 {
+			t.Errorf("Unexpected number of task polls: %d", taskPolls)
+		}
+	})
+	t.Run("waits for shard task", func(t *testing.T) {
+		var taskPolls int
+		client := &Client{driverClient: &mock.ActiveTasker{
+			ActiveTasksFunc: func(_ context.Context) ([]*driver.ActiveTask, error) {
+				taskPolls++
+				if taskPolls < 3 {
+					return []*driver.ActiveTask{
+						{Type: "database_compaction", Database: "shards/00000000-1fffffff/foo/bar.1549387711"},
+						{Type: "database_compaction", Database: "shards/20000000-3fffffff/foo.1549387711"},
+					}, nil
+				}
+				return []*driver.ActiveTask{
+					{Type: "database_compaction", Database: "shards/00000000-1fffffff/foo/bar.1549387711"},
+				}, nil
+			},
+		}}
+		db := &DB{client: client, name: "foo", driverDB: compactingDB("foo", 100, 10, 0)}
+		if err := db.CompactAndWait(context.Background(), time.Millisecond); err != nil {
+			t.Fatal(err)
+		}
+		if taskPolls != 3 {
+			t.Errorf("Unexpected number of task polls: %d", taskPolls)
+		}
+	})
+	t.Run("active tasks forbidden", func(t *testing.T) {
+		var taskPolls int
+		client := &Client{driverClient: &mock.ActiveTasker{
+			ActiveTasksFunc: func(_ context.Context) ([]*driver.ActiveTask, error) {
+				taskPolls++
+				return nil, errors.Status(StatusForbidden, "You are not a server admin.")
+			},
+		}}
+		db := &DB{client: client, name: "foo", driverDB: compactingDB("foo", 100, 10, 2)}
+		if err := db.CompactAndWait(context.Background(), time.Millisecond); err != nil {
+			t.Fatal(err)
+		}
+		if taskPolls != 1 {
+			t.Errorf("Unexpected number of task polls: %d", taskPolls)
+		}
+	})
+	t.Run("cancelled", func(t *testing.T) {
+		db := &DB{driverDB: compactingDB("foo", 100, 10, 1000)}
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		err := db.CompactAndWait(ctx, time.Millisecond)
+		if err != context.DeadlineExceeded {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	})
+}
+
+func TestCompactAll(t *testing.T) {
+	dbs := map[string]*mock.DB{
+		"fragmented": compactingDB("fragmented", 1000, 100, 2),
+		"compact":    compactingDB("compact", 110, 100, 2),
+		"small":      compactingDB("small", 50, 10, 2),
+		"also":       compactingDB("also", 600, 200, 1),
+	}
+	newClient := func() *Client {
+		return &Client{driverClient: &mock.Client{
+			AllDBsFunc: func(_ context.Context, _ map[string]interface{}) ([]string, error) {
+				return []string{"also", "compact", "fragmented", "small"}, nil
+			},
+			DBFunc: func(_ context.Context, dbName string, _ map[string]interface{}) (driver.DB, error) {
+				if db, ok := dbs[dbName]; ok {
+					return db, nil
+				}
+				return &mock.DB{
+					CompactFunc: func(_ context.Context) error {
+						return errors.Status(StatusForbidden, "compact error")
+					},
+					StatsFunc: func(_ context.Context) (*driver.DBStats, error) {
+						return &driver.DBStats{DiskSize: 1000, ActiveSize: 10}, nil
+					},
+				}, nil
+			},
+		}}
+	}
+	tests := []struct {
+		name     string
+		policy   CompactPolicy
+		expected *CompactReport
+		status   int
+		err      string
+	}{
+		{
+			name: "threshold",
+			policy: CompactPolicy{
+				Threshold:    2,
+				MinDiskSize:  100,
+				Concurrency:  2,
+				PollInterval: time.Millisecond,
+			},
+			expected: &CompactReport{
+				Compacted: []*CompactResult{
+					{DBName: "also", DiskSizeBefore: 600, DiskSizeAfter: 200},
+					{DBName: "fragmented", DiskSizeBefore: 1000, DiskSizeAfter: 100},
+				},
+				Skipped:   []string{"compact", "small"},
+				Reclaimed: 1300,
+			},
+		},
+		{
+			name: "selected dbs, no threshold",
+			policy: CompactPolicy{
+				DBs:          []string{"compact"},
+				PollInterval: time.Millisecond,
+			},
+			expected: &CompactReport{
+				Compacted: []*CompactResult{
+					{DBName: "compact", DiskSizeBefore: 110, DiskSizeAfter: 100},
+				},
+				Reclaimed: 10,
+			},
+		},
+		{
+			name: "compaction error",
+			policy: CompactPolicy{
+				DBs:          []string{"forbidden"},
+				PollInterval: time.Millisecond,
+			},
+			status: StatusForbidden,
+			err:    "compact error",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			report, err := newClient().CompactAll(context.Background(), test.policy)
+			testy.StatusError(t, test.err, test.status, err)
+			if d := diff.Interface(test.expected, report); d != nil {
+				t.Error(d)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package kivik
+
+import (
+	"context"
+
+	"github.com/go-kivik/kivik/driver"
+	"github.com/go-kivik/kivik/errors"
+)
+
+// Config represents all the config sections.
+type Config map[string]ConfigSection
+
+// ConfigSection represents all key/value pairs for a section of configuration.
+type ConfigSection map[string]string
+
+// LocalNode is the alias which may be passed as the node name to the config
+// methods, to refer to the node handling the request.
+const LocalNode = "_local"
+
+func (c *Client) configer() (driver.Configer, error) {
+	if configer, ok := c.driverClient.(driver.Configer); ok {
+		return configer, nil
+	}
+	return nil, errors.Status(StatusNotImplemented, "kivik: driver does not support Config interface")
+}
+
+func validateConfigArgs(node, section, key string, checkKey bool) error {
+	if node == "" {
+		return missingArg("node")
+	}
+	if section == "" {
+		return missingArg("section")
+	}
+	if checkKey && key == "" {
+		return missingArg("key")
+	}
+	return nil
+}
+
+// Config returns the entire server config, for the specified node. Use
+// LocalNode to refer to the node handling the request.
+//
+// See http://docs.couchdb.org/en/2.1.1/api/server/configuration.html#get--_node-node-name-_config
+func (c *Client) Config(ctx context.Context, node string) (Config, error) {
+	if node == "" {
+		return nil, missingArg("node")
+	}
+	configer, err := c.configer()
+	if err != nil {
+		return nil, err
+	}
+	config, err := configer.Config(ctx, node)
+	if err != nil {
+		return nil, err
+	}
+	c2 := make(Config, len(config))
+	for name, section := range config {
+		c2[name] = ConfigSection(section)
+	}
+	return c2, nil
+}
+
+// ConfigSection returns the requested section of the server config for the
+// specified node.
+//
+// See http://docs.couchdb.org/en/2.1.1/api/server/configuration.html#node-node-name-config-section
+func (c *Client) ConfigSection(ctx context.Context, node, section string) (ConfigSection, error) {
+	if err := validateConfigArgs(node, section, "", false); err != nil {
+		return nil, err
+	}
+	configer, err := c.configer()
+	if err != nil {
+		return nil, err
+	}
+	sec, err := configer.ConfigSection(ctx, node, section)
+	return ConfigSection(sec), err
+}
+
+// ConfigValue returns a single config value for the specified node.
+//
+// See http://docs.couchdb.org/en/2.1.1/api/server/configuration.html#get--_node-node-name-_config-section-key
+func (c *Client) ConfigValue(ctx context.Context, node, section, key string) (string, error) {
+	if err := validateConfigArgs(node, section, key, true); err != nil {
+		return "", err
+	}
+	configer, err := c.configer()
+	if err != nil {
+		return "", err
+	}
+	return configer.ConfigValue(ctx, node, section, key)
+}
+
+// SetConfigValue sets the server's config value on the specified node,
+// creating the key if it doesn't exist. It returns the old value.
+//
+// See http://docs.couchdb.org/en/2.1.1/api/server/configuration.html#put--_node-node-name-_config-section-key
+func (c *Client) SetConfigValue(ctx context.Context, node, section, key, value string) (string, error) {
+	if err := validateConfigArgs(node, section, key, true); err != nil {
+		return "", err
+	}
+	configer, err := c.configer()
+	if err != nil {
+		return "", err
+	}
+	return configer.SetConfigValue(ctx, node, section, key, value)
+}
+
+// DeleteConfigValue deletes the server's config value on the specified node.
+// It returns the old value.
+//
+// See http://docs.couchdb.org/en/2.1.1/api/server/configuration.html#delete--_node-node-name-_config-section-key
+func (c *Client) DeleteConfigValue(ctx context.Context, node, section, key string) (string, error) {
+	if err := validateConfigArgs(node, section, key, true); err != nil {
+		return "", err
+	}
+	configer, err := c.configer()
+	if err != nil {
+		return "", err
+	}
+	return configer.DeleteConfigValue(ctx, node, section, key)
+}
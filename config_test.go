@@ -0,0 +1,220 @@
+package kivik
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/flimzy/diff"
+	"github.com/flimzy/testy"
+	"github.com/go-kivik/kivik/driver"
+	"github.com/go-kivik/kivik/errors"
+	"github.com/go-kivik/kivik/mock"
+)
+
+func TestConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		client   driver.Client
+		node     string
+		expected Config
+		status   int
+		err      string
+	}{
+		{
+			name:   "missing node",
+			client: &mock.Configer{},
+			status: StatusBadRequest,
+			err:    "kivik: node required",
+		},
+		{
+			name:   "not supported",
+			client: &mock.Client{},
+			node:   LocalNode,
+			status: StatusNotImplemented,
+			err:    "kivik: driver does not support Config interface",
+		},
+		{
+			name: "error",
+			client: &mock.Configer{
+				ConfigFunc: func(_ context.Context, _ string) (driver.Config, error) {
+					return nil, errors.Status(StatusForbidden, "config error")
+				},
+			},
+			node:   LocalNode,
+			status: StatusForbidden,
+			err:    "config error",
+		},
+		{
+			name: "success",
+			client: &mock.Configer{
+				ConfigFunc: func(_ context.Context, node string) (driver.Config, error) {
+					if node != "node1@127.0.0.1" {
+						return nil, fmt.Errorf("Unexpected node: %s", node)
+					}
+					return driver.Config{
+						"httpd": driver.ConfigSection{"enable_cors": "true"},
+					}, nil
+				},
+			},
+			node: "node1@127.0.0.1",
+			expected: Config{
+				"httpd": ConfigSection{"enable_cors": "true"},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := &Client{driverClient: test.client}
+			result, err := client.Config(context.Background(), test.node)
+			testy.StatusError(t, test.err, test.status, err)
+			if d := diff.Interface(test.expected, result); d != nil {
+				t.Error(d)
+			}
+		})
+	}
+}
+
+func TestConfigSection(t *testing.T) {
+	tests := []struct {
+		name     string
+		client   driver.Client
+		node     string
+		section  string
+		expected ConfigSection
+		status   int
+		err      string
+	}{
+		{
+			name:   "missing section",
+			client: &mock.Configer{},
+			node:   LocalNode,
+			status: StatusBadRequest,
+			err:    "kivik: section required",
+		},
+		{
+			name:    "not supported",
+			client:  &mock.Client{},
+			node:    LocalNode,
+			section: "httpd",
+			status:  StatusNotImplemented,
+			err:     "kivik: driver does not support Config interface",
+		},
+		{
+			name: "success",
+			client: &mock.Configer{
+				ConfigSectionFunc: func(_ context.Context, node, section string) (driver.ConfigSection, error) {
+					if node != LocalNode || section != "httpd" {
+						return nil, fmt.Errorf("Unexpected args: %s %s", node, section)
+					}
+					return driver.ConfigSection{"enable_cors": "true"}, nil
+				},
+			},
+			node:     LocalNode,
+			section:  "httpd",
+			expected: ConfigSection{"enable_cors": "true"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := &Client{driverClient: test.client}
+			result, err := client.ConfigSection(context.Background(), test.node, test.section)
+			testy.StatusError(t, test.err, test.status, err)
+			if d := diff.Interface(test.expected, result); d != nil {
+				t.Error(d)
+			}
+		})
+	}
+}
+
+func TestConfigValues(t *testing.T) {
+	configer := &mock.Configer{
+		ConfigValueFunc: func(_ context.Context, node, section, key string) (string, error) {
+			return fmt.Sprintf("get %s/%s/%s", node, section, key), nil
+		},
+		SetConfigValueFunc: func(_ context.Context, node, section, key, value string) (string, error) {
+			return fmt.Sprintf("set %s/%s/%s=%s", node, section, key, value), nil
+		},
+		DeleteConfigValueFunc: func(_ context.Context, node, section, key string) (string, error) {
+			return fmt.Sprintf("delete %s/%s/%s", node, section, key), nil
+		},
+	}
+	tests := []struct {
+		name     string
+		client   driver.Client
+		call     func(*Client) (string, error)
+		expected string
+		status   int
+		err      string
+	}{
+		{
+			name:   "ConfigValue, missing key",
+			client: configer,
+			call: func(c *Client) (string, error) {
+				return c.ConfigValue(context.Background(), LocalNode, "httpd", "")
+			},
+			status: StatusBadRequest,
+			err:    "kivik: key required",
+		},
+		{
+			name:   "ConfigValue, not supported",
+			client: &mock.Client{},
+			call: func(c *Client) (string, error) {
+				return c.ConfigValue(context.Background(), LocalNode, "httpd", "enable_cors")
+			},
+			status: StatusNotImplemented,
+			err:    "kivik: driver does not support Config interface",
+		},
+		{
+			name:   "ConfigValue",
+			client: configer,
+			call: func(c *Client) (string, error) {
+				return c.ConfigValue(context.Background(), LocalNode, "httpd", "enable_cors")
+			},
+			expected: "get _local/httpd/enable_cors",
+		},
+		{
+			name:   "SetConfigValue, missing node",
+			client: configer,
+			call: func(c *Client) (string, error) {
+				return c.SetConfigValue(context.Background(), "", "httpd", "enable_cors", "true")
+			},
+			status: StatusBadRequest,
+			err:    "kivik: node required",
+		},
+		{
+			name:   "SetConfigValue",
+			client: configer,
+			call: func(c *Client) (string, error) {
+				return c.SetConfigValue(context.Background(), LocalNode, "cors", "origins", "*")
+			},
+			expected: "set _local/cors/origins=*",
+		},
+		{
+			name:   "DeleteConfigValue, missing section",
+			client: configer,
+			call: func(c *Client) (string, error) {
+				return c.DeleteConfigValue(context.Background(), LocalNode, "", "origins")
+			},
+			status: StatusBadRequest,
+			err:    "kivik: section required",
+		},
+		{
+			name:   "DeleteConfigValue",
+			client: configer,
+			call: func(c *Client) (string, error) {
+				return c.DeleteConfigValue(context.Background(), LocalNode, "cors", "origins")
+			},
+			expected: "delete _local/cors/origins",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := test.call(&Client{driverClient: test.client})
+			testy.StatusError(t, test.err, test.status, err)
+			if result != test.expected {
+				t.Errorf("Unexpected result: %s", result)
+			}
+		})
+	}
+}
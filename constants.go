@@ -14,6 +14,10 @@ const SessionCookieName = "AuthSession"
 // See http://docs.couchdb.org/en/2.0.0/intro/security.html#org-couchdb-user
 const UserPrefix = "org.couchdb.user:"
 
+// UsersDB is the name of the CouchDB authentication database.
+// See http://docs.couchdb.org/en/2.0.0/intro/security.html#authentication-database
+const UsersDB = "_users"
+
 // EndKeySuffix is a high Unicode character (0xfff0) useful for appending to an
 // endkey argument, when doing a ranged search, as described here:
 // http://couchdb.readthedocs.io/en/latest/ddocs/views/collation.html#string-ranges
@@ -64,6 +68,10 @@ const (
 	// Kivik for optional features which are not implemented by some drivers.
 	StatusNotImplemented = 501
 
+	// StatusServiceUnavailable is returned by CouchDB 2.x when the server is
+	// not ready to accept requests, such as when in maintenance mode.
+	StatusServiceUnavailable = 503
+
 	// Error status over 600 are obviously not proper HTTP errors at all. They
 	// are used for kivik-generated errors of various types.
 
@@ -36,6 +36,9 @@ func (db *DB) AllDocs(ctx context.Context, options ...Options) (*Rows, error) {
 		return nil, err
 	}
 	rowsi, err := db.driverDB.AllDocs(ctx, opts)
+	if db.client.renewSession(ctx, err) {
+		rowsi, err = db.driverDB.AllDocs(ctx, opts)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -53,6 +56,9 @@ func (db *DB) Query(ctx context.Context, ddoc, view string, options ...Options)
 	ddoc = strings.TrimPrefix(ddoc, "_design/")
 	view = strings.TrimPrefix(view, "_view/")
 	rowsi, err := db.driverDB.Query(ctx, ddoc, view, opts)
+	if db.client.renewSession(ctx, err) {
+		rowsi, err = db.driverDB.Query(ctx, ddoc, view, opts)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -82,7 +88,14 @@ type Row struct {
 	// typically returned by ScanDoc.
 	Err error
 
-	// Attachments is experimental
+	// Attachments provides access to the document's attachments when they
+	// are streamed separately from the document, such as when Get is called
+	// with the 'attachments' option, and the driver supports multipart
+	// responses. In this case, Body contains only attachment stubs, and each
+	// attachment may be read, or skipped, in turn without buffering the
+	// others. Attachments is nil when the driver does not stream attachments.
+	//
+	// Attachments is experimental.
 	Attachments *AttachmentsIterator
 }
 
@@ -108,6 +121,9 @@ func (db *DB) Get(ctx context.Context, docID string, options ...Options) *Row {
 		return &Row{Err: err}
 	}
 	doc, err := db.driverDB.Get(ctx, docID, opts)
+	if db.client.renewSession(ctx, err) {
+		doc, err = db.driverDB.Get(ctx, docID, opts)
+	}
 	if err != nil {
 		return &Row{Err: err}
 	}
@@ -156,7 +172,12 @@ func (db *DB) CreateDoc(ctx context.Context, doc interface{}, options ...Options
 	if err != nil {
 		return "", "", err
 	}
-	return db.driverDB.CreateDoc(ctx, doc, opts)
+	docID, rev, err = db.driverDB.CreateDoc(ctx, doc, opts)
+	// A reader cannot be sent again, so is not retried.
+	if _, isReader := doc.(io.Reader); !isReader && db.client.renewSession(ctx, err) {
+		docID, rev, err = db.driverDB.CreateDoc(ctx, doc, opts)
+	}
+	return docID, rev, err
 }
 
 // normalizeFromJSON unmarshals a []byte, json.RawMessage or io.Reader to a
@@ -229,6 +250,11 @@ func extractDocID(i interface{}) (string, bool) {
 //  - A []byte value, containing a valid JSON document
 //  - A json.RawMessage value containing a valid JSON document
 //  - An io.Reader, from which a valid JSON document may be read.
+//
+// If doc is a map or struct whose _attachments field holds Attachments (or
+// *Attachments), and the driver supports it, the document and its
+// attachments are stored in a single multipart/related request. Otherwise
+// the attachments are inlined in the document, base64-encoded.
 func (db *DB) Put(ctx context.Context, docID string, doc interface{}, options ...Options) (rev string, err error) {
 	if docID == "" {
 		return "", missingArg("docID")
@@ -241,7 +267,20 @@ func (db *DB) Put(ctx context.Context, docID string, doc interface{}, options ..
 	if err != nil {
 		return "", err
 	}
-	return db.driverDB.Put(ctx, docID, i, opts)
+	if putter, ok := db.driverDB.(driver.MultipartPutter); ok {
+		stripped, atts, err := extractAttachments(i)
+		if err != nil {
+			return "", err
+		}
+		if atts != nil {
+			return putter.PutMultipart(ctx, docID, stripped, driverAttachments(atts), opts)
+		}
+	}
+	rev, err = db.driverDB.Put(ctx, docID, i, opts)
+	if db.client.renewSession(ctx, err) {
+		rev, err = db.driverDB.Put(ctx, docID, i, opts)
+	}
+	return rev, err
 }
 
 // Delete marks the specified document as deleted.
@@ -253,7 +292,11 @@ func (db *DB) Delete(ctx context.Context, docID, rev string, options ...Options)
 	if err != nil {
 		return "", err
 	}
-	return db.driverDB.Delete(ctx, docID, rev, opts)
+	newRev, err = db.driverDB.Delete(ctx, docID, rev, opts)
+	if db.client.renewSession(ctx, err) {
+		newRev, err = db.driverDB.Delete(ctx, docID, rev, opts)
+	}
+	return newRev, err
 }
 
 // Flush requests a flush of disk cache to disk or other permanent storage.
@@ -288,20 +331,83 @@ type DBStats struct {
 	// ExternalSize is the size of the documents in the database, as represented
 	// as JSON, before compression.
 	ExternalSize int64 `json:"-"`
+	// Cluster reports the sharding and quorum configuration of the database.
+	// It is nil for servers which do not support clustering.
+	Cluster *ClusterConfig `json:"cluster,omitempty"`
+	// PurgeSeq is the current purge sequence for the database. Servers which
+	// report an integer purge sequence, such as CouchDB 1.x, have it
+	// converted to its decimal representation.
+	PurgeSeq string `json:"-"`
+	// InstanceStartTime is the time the database was opened, in microseconds
+	// since the epoch. CouchDB 2.x always reports "0".
+	InstanceStartTime string `json:"instance_start_time"`
+	// Partitioned is true if the database is partitioned.
+	Partitioned bool `json:"-"`
+	// RawResponse is the raw response body as returned by the server, useful
+	// for backends which provide additional fields.
+	RawResponse json.RawMessage `json:"-"`
+}
+
+// ClusterConfig is the sharding and quorum configuration of a clustered
+// database.
+type ClusterConfig struct {
+	// Replicas is the number of copies of each document (n).
+	Replicas int `json:"n"`
+	// Shards is the number of range partitions (q).
+	Shards int `json:"q"`
+	// ReadQuorum is the read quorum (r).
+	ReadQuorum int `json:"r"`
+	// WriteQuorum is the write quorum (w).
+	WriteQuorum int `json:"w"`
+}
+
+// Fragmentation returns the proportion of DiskSize which is not used by active
+// data, and would be freed by compaction, from 0 to 1. It returns 0 if
+// DiskSize is not known.
+func (s *DBStats) Fragmentation() float64 {
+	if s.DiskSize <= 0 || s.ActiveSize >= s.DiskSize {
+		return 0
+	}
+	return float64(s.DiskSize-s.ActiveSize) / float64(s.DiskSize)
+}
+
+func newDBStats(s *driver.DBStats) *DBStats {
+	stats := &DBStats{
+		Name:              s.Name,
+		CompactRunning:    s.CompactRunning,
+		DocCount:          s.DocCount,
+		DeletedCount:      s.DeletedCount,
+		UpdateSeq:         s.UpdateSeq,
+		DiskSize:          s.DiskSize,
+		ActiveSize:        s.ActiveSize,
+		ExternalSize:      s.ExternalSize,
+		PurgeSeq:          s.PurgeSeq,
+		InstanceStartTime: s.InstanceStartTime,
+		Partitioned:       s.Partitioned,
+		RawResponse:       s.RawResponse,
+	}
+	if s.Cluster != nil {
+		cluster := ClusterConfig(*s.Cluster)
+		stats.Cluster = &cluster
+	}
+	return stats
 }
 
 // Stats returns database statistics.
 func (db *DB) Stats(ctx context.Context) (*DBStats, error) {
 	i, err := db.driverDB.Stats(ctx)
+	if db.client.renewSession(ctx, err) {
+		i, err = db.driverDB.Stats(ctx)
+	}
 	if err != nil {
 		return nil, err
 	}
-	stats := DBStats(*i)
-	return &stats, nil
+	return newDBStats(i), nil
 }
 
 // Compact begins compaction of the database. Check the CompactRunning field
-// returned by Info() to see if the compaction has completed.
+// returned by Stats() to see if the compaction has completed, or use
+// CompactAndWait.
 // See http://docs.couchdb.org/en/2.0.0/api/database/compact.html#db-compact
 func (db *DB) Compact(ctx context.Context) error {
 	return db.driverDB.Compact(ctx)
@@ -331,6 +437,7 @@ func (db *DB) Security(ctx context.Context) (*Security, error) {
 	return &Security{
 		Admins:  Members(s.Admins),
 		Members: Members(s.Members),
+		Extra:   s.Extra,
 	}, err
 }
 
@@ -343,6 +450,7 @@ func (db *DB) SetSecurity(ctx context.Context, security *Security) error {
 	sec := &driver.Security{
 		Admins:  driver.Members(security.Admins),
 		Members: driver.Members(security.Members),
+		Extra:   security.Extra,
 	}
 	return db.driverDB.SetSecurity(ctx, sec)
 }
@@ -379,6 +487,17 @@ func (db *DB) Copy(ctx context.Context, targetID, sourceID string, options ...Op
 
 // PutAttachment uploads the supplied content as an attachment to the specified
 // document.
+//
+// The MD5 checksum of the content is computed as it is streamed to the
+// server. If att.Digest is set prior to the call, it must be an MD5 digest.
+// The checksum is compared against it, and against any MD5 digest reported by
+// the driver, only for attachments stored uncompressed, as the digest of a
+// compressed attachment, such as one returned by GetAttachment, is that of the
+// compressed data. On mismatch,
+// the new revision is returned along with an error with status
+// StatusBadResponse, as the attachment has already been stored. On success,
+// att.Digest is set to the digest of the content sent, which differs from the
+// digest reported by the server if it compressed the attachment.
 func (db *DB) PutAttachment(ctx context.Context, docID, rev string, att *Attachment, options ...Options) (newRev string, err error) {
 	if docID == "" {
 		return "", missingArg("docID")
@@ -386,15 +505,45 @@ func (db *DB) PutAttachment(ctx context.Context, docID, rev string, att *Attachm
 	if e := att.validate(); e != nil {
 		return "", e
 	}
+	expected, verify := parseMD5Digest(att.Digest)
+	if att.Digest != "" && !verify {
+		return "", errors.Statusf(StatusBadRequest, "kivik: invalid digest: %s", att.Digest)
+	}
 	opts, err := mergeOptions(options...)
 	if err != nil {
 		return "", err
 	}
 	a := driver.Attachment(*att)
-	return db.driverDB.PutAttachment(ctx, docID, rev, &a, opts)
+	a.Digest = ""
+	if a.Content == nil {
+		a.Content = nilContent
+	}
+	content := newMD5Reader(a.Content)
+	a.Content = content
+	newRev, err = db.driverDB.PutAttachment(ctx, docID, rev, &a, opts)
+	if err != nil {
+		return "", err
+	}
+	sum := content.sum()
+	if verify && att.storedIdentity() && sum != expected {
+		return newRev, digestMismatch(expected, sum)
+	}
+	stored := Attachment(a)
+	if returned, ok := parseMD5Digest(a.Digest); ok && stored.storedIdentity() && sum != returned {
+		return newRev, digestMismatch(returned, sum)
+	}
+	att.Digest = sum.Digest()
+	return newRev, nil
 }
 
 // GetAttachment returns a file attachment associated with the document.
+//
+// If the driver reports a valid MD5 digest for the attachment, and the
+// attachment is known to be stored uncompressed, the returned Content computes
+// the checksum of the data as it is read, and returns an error with status
+// StatusBadResponse in place of io.EOF if it does not match. Attachments which
+// the server may have compressed, such as text and JSON, are not verified, as
+// their digest is that of the compressed data.
 func (db *DB) GetAttachment(ctx context.Context, docID, rev, filename string, options ...Options) (*Attachment, error) {
 	if docID == "" {
 		return nil, missingArg("docID")
@@ -407,10 +556,65 @@ func (db *DB) GetAttachment(ctx context.Context, docID, rev, filename string, op
 		return nil, e
 	}
 	att, err := db.driverDB.GetAttachment(ctx, docID, rev, filename, opts)
+	if db.client.renewSession(ctx, err) {
+		att, err = db.driverDB.GetAttachment(ctx, docID, rev, filename, opts)
+	}
 	if err != nil {
 		return nil, err
 	}
 	a := Attachment(*att)
+	if expected, ok := parseMD5Digest(a.Digest); ok && a.Content != nil && a.storedIdentity() {
+		a.Content = &verifyingReader{
+			md5Reader: newMD5Reader(a.Content),
+			expected:  expected,
+		}
+	}
+	return &a, nil
+}
+
+// GetAttachmentRange returns length bytes of a file attachment associated
+// with the document, starting at offset, following HTTP Range semantics. A
+// negative length indicates the remainder of the attachment. The returned
+// Attachment's Size is the length of the range, or -1 if unknown. If offset is
+// beyond the end of the attachment, an error with status
+// StatusRequestedRangeNotSatisfiable is returned.
+//
+// The attachment's digest refers to the complete content, so partial content
+// is not verified.
+func (db *DB) GetAttachmentRange(ctx context.Context, docID, rev, filename string, offset, length int64, options ...Options) (*Attachment, error) {
+	if docID == "" {
+		return nil, missingArg("docID")
+	}
+	if filename == "" {
+		return nil, missingArg("filename")
+	}
+	if offset < 0 {
+		return nil, errors.Status(StatusBadRequest, "kivik: offset must not be negative")
+	}
+	opts, err := mergeOptions(options...)
+	if err != nil {
+		return nil, err
+	}
+	if ranger, ok := db.driverDB.(driver.AttachmentRangeGetter); ok {
+		att, err := ranger.GetAttachmentRange(ctx, docID, rev, filename, offset, length, opts)
+		if err != nil {
+			return nil, err
+		}
+		a := Attachment(*att)
+		if a.Content == nil {
+			a.Content = nilContent
+		}
+		return &a, nil
+	}
+	att, err := db.driverDB.GetAttachment(ctx, docID, rev, filename, opts)
+	if err != nil {
+		return nil, err
+	}
+	a := Attachment(*att)
+	if err := a.seek(offset, length); err != nil {
+		_ = a.Content.Close()
+		return nil, err
+	}
 	return &a, nil
 }
 
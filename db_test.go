@@ -330,6 +330,37 @@ func TestStats(t *testing.T) {
 			},
 			expected: &DBStats{Name: "foo"},
 		},
+		{
+			name: "extended",
+			db: &DB{
+				driverDB: &mock.DB{
+					StatsFunc: func(_ context.Context) (*driver.DBStats, error) {
+						return &driver.DBStats{
+							Name:              "foo",
+							DiskSize:          300,
+							ActiveSize:        200,
+							ExternalSize:      100,
+							Cluster:           &driver.ClusterConfig{Replicas: 3, Shards: 8, ReadQuorum: 2, WriteQuorum: 2},
+							PurgeSeq:          "0-xxx",
+							InstanceStartTime: "0",
+							Partitioned:       true,
+							RawResponse:       json.RawMessage(`{"db_name":"foo"}`),
+						}, nil
+					},
+				},
+			},
+			expected: &DBStats{
+				Name:              "foo",
+				DiskSize:          300,
+				ActiveSize:        200,
+				ExternalSize:      100,
+				Cluster:           &ClusterConfig{Replicas: 3, Shards: 8, ReadQuorum: 2, WriteQuorum: 2},
+				PurgeSeq:          "0-xxx",
+				InstanceStartTime: "0",
+				Partitioned:       true,
+				RawResponse:       json.RawMessage(`{"db_name":"foo"}`),
+			},
+		},
 	}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
@@ -342,6 +373,37 @@ func TestStats(t *testing.T) {
 	}
 }
 
+func TestDBStatsFragmentation(t *testing.T) {
+	tests := []struct {
+		name     string
+		stats    *DBStats
+		expected float64
+	}{
+		{
+			name:     "unknown size",
+			stats:    &DBStats{},
+			expected: 0,
+		},
+		{
+			name:     "fragmented",
+			stats:    &DBStats{DiskSize: 400, ActiveSize: 100},
+			expected: 0.75,
+		},
+		{
+			name:     "active exceeds disk",
+			stats:    &DBStats{DiskSize: 100, ActiveSize: 150},
+			expected: 0,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if result := test.stats.Fragmentation(); result != test.expected {
+				t.Errorf("Unexpected result: %v", result)
+			}
+		})
+	}
+}
+
 func TestCompact(t *testing.T) {
 	expected := "compact error"
 	db := &DB{
@@ -888,6 +950,37 @@ func TestNormalizeFromJSON(t *testing.T) {
 	}
 }
 
+func newTestAttachments() Attachments {
+	return Attachments{
+		"foo.txt": &Attachment{ContentType: "text/plain", Content: body("foo")},
+		"bar.txt": &Attachment{ContentType: "text/plain", Content: body("bar")},
+	}
+}
+
+func putMultipartFunc(_ context.Context, docID string, doc map[string]interface{}, atts []*driver.Attachment, opts map[string]interface{}) (string, error) {
+	if docID != "foo" {
+		return "", errors.Errorf("Unexpected docID: %s", docID)
+	}
+	if d := diff.Interface(map[string]interface{}{"foo": "bar"}, doc); d != nil {
+		return "", errors.Errorf("Unexpected doc: %s", d)
+	}
+	if d := diff.Interface(testOptions, opts); d != nil {
+		return "", errors.Errorf("Unexpected opts: %s", d)
+	}
+	var result []string
+	for _, att := range atts {
+		content, err := ioutil.ReadAll(att.Content)
+		if err != nil {
+			return "", err
+		}
+		result = append(result, fmt.Sprintf("%s %s %s", att.Filename, att.ContentType, content))
+	}
+	if d := diff.Interface([]string{"bar.txt text/plain bar", "foo.txt text/plain foo"}, result); d != nil {
+		return "", errors.Errorf("Unexpected attachments: %s", d)
+	}
+	return "1-xxx", nil
+}
+
 func TestPut(t *testing.T) {
 	putFunc := func(_ context.Context, docID string, doc interface{}, opts map[string]interface{}) (string, error) {
 		expectedDocID := "foo"
@@ -994,6 +1087,77 @@ func TestPut(t *testing.T) {
 			status: StatusUnknownError,
 			err:    "errorReader",
 		},
+		{
+			name: "multipart, map",
+			db: &DB{
+				driverDB: &mock.MultipartPutter{
+					PutMultipartFunc: putMultipartFunc,
+				},
+			},
+			docID: "foo",
+			input: map[string]interface{}{
+				"foo":          "bar",
+				"_attachments": newTestAttachments(),
+			},
+			options: testOptions,
+			newRev:  "1-xxx",
+		},
+		{
+			name: "multipart, struct",
+			db: &DB{
+				driverDB: &mock.MultipartPutter{
+					PutMultipartFunc: putMultipartFunc,
+				},
+			},
+			docID: "foo",
+			input: &struct {
+				Foo         string       `json:"foo"`
+				Attachments *Attachments `json:"_attachments,omitempty"`
+			}{
+				Foo:         "bar",
+				Attachments: func() *Attachments { a := newTestAttachments(); return &a }(),
+			},
+			options: testOptions,
+			newRev:  "1-xxx",
+		},
+		{
+			name: "multipart, no attachments",
+			db: &DB{
+				driverDB: &mock.MultipartPutter{
+					DB: &mock.DB{
+						PutFunc: putFunc,
+					},
+				},
+			},
+			docID:   "foo",
+			input:   map[string]interface{}{"foo": "bar"},
+			options: testOptions,
+			newRev:  "1-xxx",
+		},
+		{
+			name: "inline attachments",
+			db: &DB{
+				driverDB: &mock.DB{
+					PutFunc: func(_ context.Context, _ string, doc interface{}, _ map[string]interface{}) (string, error) {
+						expected := `{"_attachments":{"bar.txt":{"content_type":"text/plain","data":"YmFy"},"foo.txt":{"content_type":"text/plain","data":"Zm9v"}},"foo":"bar"}`
+						data, err := json.Marshal(doc)
+						if err != nil {
+							return "", err
+						}
+						if d := diff.JSON([]byte(expected), data); d != nil {
+							return "", errors.Errorf("Unexpected doc: %s", d)
+						}
+						return "1-xxx", nil
+					},
+				},
+			},
+			docID: "foo",
+			input: map[string]interface{}{
+				"foo":          "bar",
+				"_attachments": newTestAttachments(),
+			},
+			newRev: "1-xxx",
+		},
 	}
 	for _, test := range tests {
 		func(test putTest) {
@@ -1236,7 +1400,8 @@ func TestPutAttachment(t *testing.T) {
 		status     int
 		err        string
 
-		body string
+		body   string
+		digest string
 	}{
 		{
 			name:  "db error",
@@ -1312,6 +1477,130 @@ func TestPutAttachment(t *testing.T) {
 			options: testOptions,
 			newRev:  "2-xxx",
 			body:    "Test file",
+			digest:  "md5-7ckAdFxdFddz+83As3bwDA==",
+		},
+		{
+			name:  "invalid digest",
+			docID: "foo",
+			att: &Attachment{
+				Filename: "foo.txt",
+				Digest:   "md5-foo",
+			},
+			status: StatusBadRequest,
+			err:    "kivik: invalid digest: md5-foo",
+		},
+		{
+			name:  "caller digest mismatch",
+			docID: "foo",
+			db: &DB{
+				driverDB: &mock.DB{
+					PutAttachmentFunc: func(_ context.Context, _, _ string, att *driver.Attachment, _ map[string]interface{}) (string, error) {
+						if att.Digest != "" {
+							return "", fmt.Errorf("Unexpected digest: %s", att.Digest)
+						}
+						_, err := ioutil.ReadAll(att.Content)
+						return "2-xxx", err
+					},
+				},
+			},
+			att: &Attachment{
+				Filename: "foo.txt",
+				Content:  ioutil.NopCloser(strings.NewReader("Test file")),
+				Digest:   "md5-1B2M2Y8AsgTpgAmY7PhCfg==",
+			},
+			newRev: "2-xxx",
+			status: StatusBadResponse,
+			err:    "kivik: attachment digest mismatch: expected md5-1B2M2Y8AsgTpgAmY7PhCfg==, got md5-7ckAdFxdFddz+83As3bwDA==",
+			digest: "md5-1B2M2Y8AsgTpgAmY7PhCfg==",
+		},
+		{
+			name:  "server digest mismatch",
+			docID: "foo",
+			db: &DB{
+				driverDB: &mock.DB{
+					PutAttachmentFunc: func(_ context.Context, _, _ string, att *driver.Attachment, _ map[string]interface{}) (string, error) {
+						if _, err := ioutil.ReadAll(att.Content); err != nil {
+							return "", err
+						}
+						att.Digest = "md5-1B2M2Y8AsgTpgAmY7PhCfg=="
+						return "2-xxx", nil
+					},
+				},
+			},
+			att: &Attachment{
+				Filename: "foo.txt",
+				Content:  ioutil.NopCloser(strings.NewReader("Test file")),
+			},
+			newRev: "2-xxx",
+			status: StatusBadResponse,
+			err:    "kivik: attachment digest mismatch: expected md5-1B2M2Y8AsgTpgAmY7PhCfg==, got md5-7ckAdFxdFddz+83As3bwDA==",
+		},
+		{
+			name:  "server digest of gzip encoded attachment",
+			docID: "foo",
+			db: &DB{
+				driverDB: &mock.DB{
+					PutAttachmentFunc: func(_ context.Context, _, _ string, att *driver.Attachment, _ map[string]interface{}) (string, error) {
+						if _, err := ioutil.ReadAll(att.Content); err != nil {
+							return "", err
+						}
+						att.ContentEncoding = "gzip"
+						att.Digest = "md5-1B2M2Y8AsgTpgAmY7PhCfg=="
+						return "2-xxx", nil
+					},
+				},
+			},
+			att: &Attachment{
+				Filename:    "foo.bin",
+				ContentType: "application/octet-stream",
+				Content:     ioutil.NopCloser(strings.NewReader("Test file")),
+			},
+			newRev: "2-xxx",
+			digest: "md5-7ckAdFxdFddz+83As3bwDA==",
+		},
+		{
+			name:  "server digest of compressible attachment",
+			docID: "foo",
+			db: &DB{
+				driverDB: &mock.DB{
+					PutAttachmentFunc: func(_ context.Context, _, _ string, att *driver.Attachment, _ map[string]interface{}) (string, error) {
+						if _, err := ioutil.ReadAll(att.Content); err != nil {
+							return "", err
+						}
+						att.Digest = "md5-1B2M2Y8AsgTpgAmY7PhCfg=="
+						return "2-xxx", nil
+					},
+				},
+			},
+			att: &Attachment{
+				Filename:    "foo.txt",
+				ContentType: "text/plain",
+				Content:     ioutil.NopCloser(strings.NewReader("Test file")),
+			},
+			newRev: "2-xxx",
+			digest: "md5-7ckAdFxdFddz+83As3bwDA==",
+		},
+		{
+			name:  "digests match",
+			docID: "foo",
+			db: &DB{
+				driverDB: &mock.DB{
+					PutAttachmentFunc: func(_ context.Context, _, _ string, att *driver.Attachment, _ map[string]interface{}) (string, error) {
+						if _, err := ioutil.ReadAll(att.Content); err != nil {
+							return "", err
+						}
+						att.Digest = "md5-7ckAdFxdFddz+83As3bwDA=="
+						return "2-xxx", nil
+					},
+				},
+			},
+			att: &Attachment{
+				Filename: "foo.txt",
+				Content:  ioutil.NopCloser(strings.NewReader("Test file")),
+				Digest:   "md5-7ckAdFxdFddz+83As3bwDA==",
+			},
+			newRev: "2-xxx",
+			digest: "md5-7ckAdFxdFddz+83As3bwDA==",
 		},
 	}
 	for _, test := range tests {
@@ -1321,6 +1610,9 @@ func TestPutAttachment(t *testing.T) {
 			if newRev != test.newRev {
 				t.Errorf("Unexpected newRev: %s", newRev)
 			}
+			if test.att != nil && test.att.Digest != test.digest {
+				t.Errorf("Unexpected digest: %s", test.att.Digest)
+			}
 		})
 	}
 }
@@ -1401,6 +1693,44 @@ func TestDeleteAttachment(t *testing.T) {
 	}
 }
 
+func TestAttachmentRoundTrip(t *testing.T) {
+	var stored string
+	db := &DB{
+		driverDB: &mock.DB{
+			GetAttachmentFunc: func(_ context.Context, _, _, filename string, _ map[string]interface{}) (*driver.Attachment, error) {
+				return &driver.Attachment{
+					Filename:        filename,
+					ContentType:     "text/plain",
+					ContentEncoding: "gzip",
+					Size:            9,
+					EncodedLength:   29,
+					Digest:          "md5-1B2M2Y8AsgTpgAmY7PhCfg==",
+					Content:         body("Test file"),
+				}, nil
+			},
+			PutAttachmentFunc: func(_ context.Context, _, _ string, att *driver.Attachment, _ map[string]interface{}) (string, error) {
+				content, err := ioutil.ReadAll(att.Content)
+				if err != nil {
+					return "", err
+				}
+				stored = string(content)
+				att.Digest = "md5-1B2M2Y8AsgTpgAmY7PhCfg=="
+				return "2-xxx", nil
+			},
+		},
+	}
+	att, err := db.GetAttachment(context.Background(), "foo", "", "foo.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.PutAttachment(context.Background(), "bar", "", att); err != nil {
+		t.Fatal(err)
+	}
+	if stored != "Test file" {
+		t.Errorf("Unexpected content stored: %s", stored)
+	}
+}
+
 func TestGetAttachment(t *testing.T) {
 	tests := []struct {
 		name                 string
@@ -1408,10 +1738,12 @@ func TestGetAttachment(t *testing.T) {
 		docID, rev, filename string
 		options              Options
 
-		content  string
-		expected *Attachment
-		status   int
-		err      string
+		content    string
+		expected   *Attachment
+		status     int
+		err        string
+		readStatus int
+		readErr    string
 	}{
 		{
 			name: "error",
@@ -1478,12 +1810,339 @@ func TestGetAttachment(t *testing.T) {
 			status: StatusBadRequest,
 			err:    "kivik: filename required",
 		},
+		{
+			name: "verified digest",
+			db: &DB{
+				driverDB: &mock.DB{
+					GetAttachmentFunc: func(_ context.Context, _, _, _ string, _ map[string]interface{}) (*driver.Attachment, error) {
+						return &driver.Attachment{
+							Filename: "foo.txt",
+							Digest:   "md5-DLxmEfVUC9CAmjiNyVphWw==",
+							Content:  body("Test"),
+						}, nil
+					},
+				},
+			},
+			docID:    "foo",
+			filename: "foo.txt",
+			content:  "Test",
+			expected: &Attachment{
+				Filename: "foo.txt",
+				Digest:   "md5-DLxmEfVUC9CAmjiNyVphWw==",
+			},
+		},
+		{
+			name: "digest mismatch",
+			db: &DB{
+				driverDB: &mock.DB{
+					GetAttachmentFunc: func(_ context.Context, _, _, _ string, _ map[string]interface{}) (*driver.Attachment, error) {
+						return &driver.Attachment{
+							Filename: "foo.txt",
+							Digest:   "md5-DLxmEfVUC9CAmjiNyVphWw==",
+							Content:  body("Corrupted"),
+						}, nil
+					},
+				},
+			},
+			docID:      "foo",
+			filename:   "foo.txt",
+			readStatus: StatusBadResponse,
+			readErr:    "kivik: attachment digest mismatch: expected md5-DLxmEfVUC9CAmjiNyVphWw==, got md5-Ff8rztxGUwS9GGs0oB4lHg==",
+			content:    "Corrupted",
+			expected: &Attachment{
+				Filename: "foo.txt",
+				Digest:   "md5-DLxmEfVUC9CAmjiNyVphWw==",
+			},
+		},
+		{
+			name: "gzip encoded, not verified",
+			db: &DB{
+				driverDB: &mock.DB{
+					GetAttachmentFunc: func(_ context.Context, _, _, _ string, _ map[string]interface{}) (*driver.Attachment, error) {
+						return &driver.Attachment{
+							Filename:        "foo.bin",
+							ContentType:     "application/octet-stream",
+							ContentEncoding: "gzip",
+							Size:            4,
+							EncodedLength:   24,
+							Digest:          "md5-DLxmEfVUC9CAmjiNyVphWw==",
+							Content:         body("Decompressed"),
+						}, nil
+					},
+				},
+			},
+			docID:    "foo",
+			filename: "foo.bin",
+			content:  "Decompressed",
+			expected: &Attachment{
+				Filename:        "foo.bin",
+				ContentType:     "application/octet-stream",
+				ContentEncoding: "gzip",
+				Size:            4,
+				EncodedLength:   24,
+				Digest:          "md5-DLxmEfVUC9CAmjiNyVphWw==",
+			},
+		},
+		{
+			name: "encoded length differs, not verified",
+			db: &DB{
+				driverDB: &mock.DB{
+					GetAttachmentFunc: func(_ context.Context, _, _, _ string, _ map[string]interface{}) (*driver.Attachment, error) {
+						return &driver.Attachment{
+							Filename:      "foo.bin",
+							Size:          12,
+							EncodedLength: 24,
+							Digest:        "md5-DLxmEfVUC9CAmjiNyVphWw==",
+							Content:       body("Decompressed"),
+						}, nil
+					},
+				},
+			},
+			docID:    "foo",
+			filename: "foo.bin",
+			content:  "Decompressed",
+			expected: &Attachment{
+				Filename:      "foo.bin",
+				Size:          12,
+				EncodedLength: 24,
+				Digest:        "md5-DLxmEfVUC9CAmjiNyVphWw==",
+			},
+		},
+		{
+			name: "compressible type, not verified",
+			db: &DB{
+				driverDB: &mock.DB{
+					GetAttachmentFunc: func(_ context.Context, _, _, _ string, _ map[string]interface{}) (*driver.Attachment, error) {
+						return &driver.Attachment{
+							Filename:    "foo.json",
+							ContentType: "application/json; charset=utf-8",
+							Digest:      "md5-DLxmEfVUC9CAmjiNyVphWw==",
+							Content:     body(`{"foo":"bar"}`),
+						}, nil
+					},
+				},
+			},
+			docID:    "foo",
+			filename: "foo.json",
+			content:  `{"foo":"bar"}`,
+			expected: &Attachment{
+				Filename:    "foo.json",
+				ContentType: "application/json; charset=utf-8",
+				Digest:      "md5-DLxmEfVUC9CAmjiNyVphWw==",
+			},
+		},
 	}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 			result, err := test.db.GetAttachment(context.Background(), test.docID, test.rev, test.filename, test.options)
 			testy.StatusError(t, test.err, test.status, err)
 			content, err := ioutil.ReadAll(result.Content)
+			testy.StatusError(t, test.readErr, test.readStatus, err)
+			if d := diff.Text(test.content, string(content)); d != nil {
+				t.Errorf("Unexpected content:\n%s", d)
+			}
+			_ = result.Content.Close()
+			result.Content = nil
+			if d := diff.Interface(test.expected, result); d != nil {
+				t.Error(d)
+			}
+		})
+	}
+}
+
+func TestGetAttachmentRange(t *testing.T) {
+	plainDB := func(size int64) *DB {
+		return &DB{
+			driverDB: &mock.DB{
+				GetAttachmentFunc: func(_ context.Context, _, _, _ string, _ map[string]interface{}) (*driver.Attachment, error) {
+					return &driver.Attachment{
+						Filename: "foo.txt",
+						Size:     size,
+						Digest:   "md5-DLxmEfVUC9CAmjiNyVphWw==",
+						Content:  body("0123456789"),
+					}, nil
+				},
+			},
+		}
+	}
+	tests := []struct {
+		name           string
+		db             *DB
+		docID          string
+		filename       string
+		offset, length int64
+		options        Options
+
+		content  string
+		expected *Attachment
+		status   int
+		err      string
+	}{
+		{
+			name:   "no docID",
+			status: StatusBadRequest,
+			err:    "kivik: docID required",
+		},
+		{
+			name:   "no filename",
+			docID:  "foo",
+			status: StatusBadRequest,
+			err:    "kivik: filename required",
+		},
+		{
+			name:     "negative offset",
+			docID:    "foo",
+			filename: "foo.txt",
+			offset:   -1,
+			status:   StatusBadRequest,
+			err:      "kivik: offset must not be negative",
+		},
+		{
+			name: "range getter",
+			db: &DB{
+				driverDB: &mock.AttachmentRangeGetter{
+					GetAttachmentRangeFunc: func(_ context.Context, docID, rev, filename string, offset, length int64, opts map[string]interface{}) (*driver.Attachment, error) {
+						if docID != "foo" || rev != "" || filename != "foo.txt" {
+							return nil, fmt.Errorf("Unexpected args: %s, %s, %s", docID, rev, filename)
+						}
+						if offset != 2 || length != 3 {
+							return nil, fmt.Errorf("Unexpected range: %d, %d", offset, length)
+						}
+						if d := diff.Interface(testOptions, opts); d != nil {
+							return nil, fmt.Errorf("Unexpected options:\n%s", d)
+						}
+						return &driver.Attachment{Filename: "foo.txt", Size: 3, Content: body("234")}, nil
+					},
+				},
+			},
+			docID:    "foo",
+			filename: "foo.txt",
+			offset:   2,
+			length:   3,
+			options:  testOptions,
+			content:  "234",
+			expected: &Attachment{Filename: "foo.txt", Size: 3},
+		},
+		{
+			name: "range getter error",
+			db: &DB{
+				driverDB: &mock.AttachmentRangeGetter{
+					GetAttachmentRangeFunc: func(_ context.Context, _, _, _ string, _, _ int64, _ map[string]interface{}) (*driver.Attachment, error) {
+						return nil, errors.Status(StatusRequestedRangeNotSatisfiable, "bad range")
+					},
+				},
+			},
+			docID:    "foo",
+			filename: "foo.txt",
+			status:   StatusRequestedRangeNotSatisfiable,
+			err:      "bad range",
+		},
+		{
+			name: "emulated, get error",
+			db: &DB{
+				driverDB: &mock.DB{
+					GetAttachmentFunc: func(_ context.Context, _, _, _ string, _ map[string]interface{}) (*driver.Attachment, error) {
+						return nil, errors.New("fail")
+					},
+				},
+			},
+			docID:    "foo",
+			filename: "foo.txt",
+			status:   StatusInternalServerError,
+			err:      "fail",
+		},
+		{
+			name:     "emulated",
+			db:       plainDB(10),
+			docID:    "foo",
+			filename: "foo.txt",
+			offset:   2,
+			length:   3,
+			content:  "234",
+			expected: &Attachment{Filename: "foo.txt", Size: 3, Digest: "md5-DLxmEfVUC9CAmjiNyVphWw=="},
+		},
+		{
+			name:     "emulated, to end",
+			db:       plainDB(10),
+			docID:    "foo",
+			filename: "foo.txt",
+			offset:   7,
+			length:   -1,
+			content:  "789",
+			expected: &Attachment{Filename: "foo.txt", Size: 3, Digest: "md5-DLxmEfVUC9CAmjiNyVphWw=="},
+		},
+		{
+			name:     "emulated, length beyond end",
+			db:       plainDB(10),
+			docID:    "foo",
+			filename: "foo.txt",
+			offset:   8,
+			length:   5,
+			content:  "89",
+			expected: &Attachment{Filename: "foo.txt", Size: 2, Digest: "md5-DLxmEfVUC9CAmjiNyVphWw=="},
+		},
+		{
+			name:     "emulated, unknown size",
+			db:       plainDB(-1),
+			docID:    "foo",
+			filename: "foo.txt",
+			offset:   5,
+			length:   2,
+			content:  "56",
+			expected: &Attachment{Filename: "foo.txt", Size: -1, Digest: "md5-DLxmEfVUC9CAmjiNyVphWw=="},
+		},
+		{
+			name:     "emulated, offset beyond size",
+			db:       plainDB(10),
+			docID:    "foo",
+			filename: "foo.txt",
+			offset:   10,
+			status:   StatusRequestedRangeNotSatisfiable,
+			err:      "kivik: offset 10 beyond attachment size 10",
+		},
+		{
+			name: "emulated, empty attachment without content",
+			db: &DB{
+				driverDB: &mock.DB{
+					GetAttachmentFunc: func(_ context.Context, _, _, _ string, _ map[string]interface{}) (*driver.Attachment, error) {
+						return &driver.Attachment{Filename: "foo.txt"}, nil
+					},
+				},
+			},
+			docID:    "foo",
+			filename: "foo.txt",
+			offset:   1,
+			status:   StatusRequestedRangeNotSatisfiable,
+			err:      "kivik: offset 1 beyond attachment size 0",
+		},
+		{
+			name: "range getter without content",
+			db: &DB{
+				driverDB: &mock.AttachmentRangeGetter{
+					GetAttachmentRangeFunc: func(_ context.Context, _, _, _ string, _, _ int64, _ map[string]interface{}) (*driver.Attachment, error) {
+						return &driver.Attachment{Filename: "foo.txt"}, nil
+					},
+				},
+			},
+			docID:    "foo",
+			filename: "foo.txt",
+			expected: &Attachment{Filename: "foo.txt"},
+		},
+		{
+			name:     "emulated, offset beyond content",
+			db:       plainDB(-1),
+			docID:    "foo",
+			filename: "foo.txt",
+			offset:   20,
+			status:   StatusRequestedRangeNotSatisfiable,
+			err:      "kivik: offset 20 beyond attachment size 10",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := test.db.GetAttachmentRange(context.Background(), test.docID, "", test.filename, test.offset, test.length, test.options)
+			testy.StatusError(t, test.err, test.status, err)
+			content, err := ioutil.ReadAll(result.Content)
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -0,0 +1,111 @@
+package kivik
+
+import (
+	"context"
+	"sync"
+
+	"github.com/go-kivik/kivik/driver"
+)
+
+// AllDBsOptions are the typed options for AllDBs. Zero values are omitted.
+//
+// See http://docs.couchdb.org/en/2.1.1/api/server/common.html#all-dbs
+type AllDBsOptions struct {
+	// StartKey and EndKey limit the range of database names returned.
+	StartKey string
+	EndKey   string
+	// Limit limits the number of database names returned.
+	Limit int
+	// Skip skips this number of database names.
+	Skip int
+	// Descending returns the database names in reverse order.
+	Descending bool
+}
+
+// Options converts o to an Options map, suitable for passing to AllDBs.
+func (o AllDBsOptions) Options() Options {
+	opts := Options{}
+	if o.StartKey != "" {
+		opts["startkey"] = o.StartKey
+	}
+	if o.EndKey != "" {
+		opts["endkey"] = o.EndKey
+	}
+	if o.Limit > 0 {
+		opts["limit"] = o.Limit
+	}
+	if o.Skip > 0 {
+		opts["skip"] = o.Skip
+	}
+	if o.Descending {
+		opts["descending"] = true
+	}
+	return opts
+}
+
+// maxDBsStatsWorkers is the maximum number of concurrent Stats requests made
+// when emulating DBsStats.
+const maxDBsStatsWorkers = 10
+
+// DBsStats returns the statistics of each of the requested databases, in the
+// same order as dbNames. The entry for any database which does not exist is
+// nil.
+//
+// If the driver supports it, a single request is made, as with
+// POST /_dbs_info. Otherwise, the statistics of each database are requested
+// concurrently, and the first error other than StatusNotFound is returned.
+//
+// See http://docs.couchdb.org/en/2.1.1/api/server/common.html#dbs-info
+func (c *Client) DBsStats(ctx context.Context, dbNames []string) ([]*DBStats, error) {
+	if statser, ok := c.driverClient.(driver.DBsStatser); ok {
+		stats, err := statser.DBsStats(ctx, dbNames)
+		if err != nil {
+			return nil, err
+		}
+		result := make([]*DBStats, len(stats))
+		for i, s := range stats {
+			if s != nil {
+				result[i] = newDBStats(s)
+			}
+		}
+		return result, nil
+	}
+	return c.emulateDBsStats(ctx, dbNames)
+}
+
+func (c *Client) emulateDBsStats(ctx context.Context, dbNames []string) ([]*DBStats, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	result := make([]*DBStats, len(dbNames))
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	sem := make(chan struct{}, maxDBsStatsWorkers)
+	for i, dbName := range dbNames {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, dbName string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			db, err := c.DB(ctx, dbName)
+			if err == nil {
+				result[i], err = db.Stats(ctx)
+			}
+			if err != nil && StatusCode(err) != StatusNotFound {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = err
+					cancel()
+				}
+				mu.Unlock()
+			}
+		}(i, dbName)
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return result, nil
+}
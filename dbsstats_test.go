@@ -0,0 +1,136 @@
+package kivik
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/flimzy/diff"
+	"github.com/flimzy/testy"
+	"github.com/go-kivik/kivik/driver"
+	"github.com/go-kivik/kivik/errors"
+	"github.com/go-kivik/kivik/mock"
+)
+
+func TestAllDBsOptions(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     AllDBsOptions
+		expected Options
+	}{
+		{
+			name:     "zero",
+			expected: Options{},
+		},
+		{
+			name: "all",
+			opts: AllDBsOptions{
+				StartKey:   "a",
+				EndKey:     "b",
+				Limit:      10,
+				Skip:       5,
+				Descending: true,
+			},
+			expected: Options{
+				"startkey":   "a",
+				"endkey":     "b",
+				"limit":      10,
+				"skip":       5,
+				"descending": true,
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if d := diff.Interface(test.expected, test.opts.Options()); d != nil {
+				t.Error(d)
+			}
+		})
+	}
+}
+
+func TestDBsStats(t *testing.T) {
+	statsDB := func(dbName string) driver.DB {
+		return &mock.DB{
+			StatsFunc: func(_ context.Context) (*driver.DBStats, error) {
+				switch dbName {
+				case "missing":
+					return nil, errors.Status(StatusNotFound, "not found")
+				case "forbidden":
+					return nil, errors.Status(StatusForbidden, "forbidden")
+				}
+				return &driver.DBStats{Name: dbName, DocCount: int64(len(dbName))}, nil
+			},
+		}
+	}
+	emulated := &mock.Client{
+		DBFunc: func(_ context.Context, dbName string, _ map[string]interface{}) (driver.DB, error) {
+			return statsDB(dbName), nil
+		},
+	}
+	tests := []struct {
+		name     string
+		client   driver.Client
+		dbNames  []string
+		expected []*DBStats
+		status   int
+		err      string
+	}{
+		{
+			name: "driver error",
+			client: &mock.DBsStatser{
+				DBsStatsFunc: func(_ context.Context, _ []string) ([]*driver.DBStats, error) {
+					return nil, errors.Status(StatusBadRequest, "too many keys")
+				},
+			},
+			dbNames: []string{"foo"},
+			status:  StatusBadRequest,
+			err:     "too many keys",
+		},
+		{
+			name: "driver support",
+			client: &mock.DBsStatser{
+				DBsStatsFunc: func(_ context.Context, dbNames []string) ([]*driver.DBStats, error) {
+					if d := diff.Interface([]string{"foo", "missing"}, dbNames); d != nil {
+						return nil, fmt.Errorf("Unexpected names:\n%s", d)
+					}
+					return []*driver.DBStats{{Name: "foo", DocCount: 3}, nil}, nil
+				},
+			},
+			dbNames:  []string{"foo", "missing"},
+			expected: []*DBStats{{Name: "foo", DocCount: 3}, nil},
+		},
+		{
+			name:    "emulated",
+			client:  emulated,
+			dbNames: []string{"foo", "missing", "barbaz"},
+			expected: []*DBStats{
+				{Name: "foo", DocCount: 3},
+				nil,
+				{Name: "barbaz", DocCount: 6},
+			},
+		},
+		{
+			name:    "emulated, error",
+			client:  emulated,
+			dbNames: []string{"foo", "forbidden"},
+			status:  StatusForbidden,
+			err:     "forbidden",
+		},
+		{
+			name:     "emulated, no databases",
+			client:   emulated,
+			expected: []*DBStats{},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := &Client{driverClient: test.client}
+			result, err := client.DBsStats(context.Background(), test.dbNames)
+			testy.StatusError(t, test.err, test.status, err)
+			if d := diff.Interface(test.expected, result); d != nil {
+				t.Error(d)
+			}
+		})
+	}
+}
@@ -0,0 +1,330 @@
+package kivik
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/go-kivik/kivik/errors"
+)
+
+// DesignDocPrefix is the mandatory prefix for design document IDs.
+const DesignDocPrefix = "_design/"
+
+// DesignDoc represents a CouchDB design document.
+// See http://docs.couchdb.org/en/2.1.1/api/ddoc/common.html
+type DesignDoc struct {
+	// ID is the document ID. If it does not begin with '_design/', the
+	// prefix is added when the document is written by SyncDesignDocs.
+	ID string `json:"_id"`
+	// Rev is the document revision.
+	Rev string `json:"_rev,omitempty"`
+	// Language is the language of the design document functions. If empty,
+	// CouchDB assumes "javascript".
+	Language string `json:"language,omitempty"`
+	// Views is a map of view names to view definitions.
+	Views map[string]View `json:"views,omitempty"`
+	// Filters is a map of filter function names to their source.
+	Filters map[string]string `json:"filters,omitempty"`
+	// Updates is a map of update handler names to their source.
+	Updates map[string]string `json:"updates,omitempty"`
+	// Shows is a map of show function names to their source.
+	Shows map[string]string `json:"shows,omitempty"`
+	// Lists is a map of list function names to their source.
+	Lists map[string]string `json:"lists,omitempty"`
+	// ValidateDocUpdate is the source of the validate_doc_update function.
+	ValidateDocUpdate string `json:"validate_doc_update,omitempty"`
+	// Options are the view indexing options.
+	Options *DesignDocOptions `json:"options,omitempty"`
+	// Extra holds any fields not modeled above, such as autoupdate, rewrites
+	// or indexes, which are preserved when the design document is read and
+	// written as JSON.
+	Extra map[string]interface{} `json:"-"`
+}
+
+type designDoc DesignDoc
+
+// MarshalJSON satisfies the json.Marshaler interface.
+func (d DesignDoc) MarshalJSON() ([]byte, error) {
+	return marshalWithExtra(designDoc(d), d.Extra)
+}
+
+// UnmarshalJSON satisfies the json.Unmarshaler interface.
+func (d *DesignDoc) UnmarshalJSON(data []byte) error {
+	var ddoc designDoc
+	if err := json.Unmarshal(data, &ddoc); err != nil {
+		return err
+	}
+	extra, err := unmodeledFields(data, ddoc)
+	if err != nil {
+		return err
+	}
+	*d = DesignDoc(ddoc)
+	d.Extra = extra
+	return nil
+}
+
+// View is a view definition within a design document.
+type View struct {
+	// Map is the source of the map function.
+	Map string `json:"map"`
+	// Reduce is the source, or built-in name, of the reduce function. It is
+	// empty for views without a reduce function.
+	Reduce string `json:"reduce,omitempty"`
+	// Extra holds any fields not modeled above, such as per-view options.
+	Extra map[string]interface{} `json:"-"`
+}
+
+type view View
+
+// MarshalJSON satisfies the json.Marshaler interface.
+func (v View) MarshalJSON() ([]byte, error) {
+	return marshalWithExtra(view(v), v.Extra)
+}
+
+// UnmarshalJSON satisfies the json.Unmarshaler interface.
+func (v *View) UnmarshalJSON(data []byte) error {
+	var x view
+	if err := json.Unmarshal(data, &x); err != nil {
+		return err
+	}
+	extra, err := unmodeledFields(data, x)
+	if err != nil {
+		return err
+	}
+	*v = View(x)
+	v.Extra = extra
+	return nil
+}
+
+// DesignDocOptions are the options of a design document.
+type DesignDocOptions struct {
+	// Partitioned determines whether the design document's views are
+	// partitioned, in a partitioned database. If nil, the database default
+	// is used.
+	Partitioned *bool `json:"partitioned,omitempty"`
+	// LocalSeq causes the local sequence number of each document to be made
+	// available to map functions.
+	LocalSeq bool `json:"local_seq,omitempty"`
+	// IncludeDesign causes map functions to be called for design documents
+	// as well as regular documents.
+	IncludeDesign bool `json:"include_design,omitempty"`
+	// Extra holds any options not modeled above.
+	Extra map[string]interface{} `json:"-"`
+}
+
+type designDocOptions DesignDocOptions
+
+// MarshalJSON satisfies the json.Marshaler interface.
+func (o DesignDocOptions) MarshalJSON() ([]byte, error) {
+	return marshalWithExtra(designDocOptions(o), o.Extra)
+}
+
+// UnmarshalJSON satisfies the json.Unmarshaler interface.
+func (o *DesignDocOptions) UnmarshalJSON(data []byte) error {
+	var opts designDocOptions
+	if err := json.Unmarshal(data, &opts); err != nil {
+		return err
+	}
+	extra, err := unmodeledFields(data, opts)
+	if err != nil {
+		return err
+	}
+	*o = DesignDocOptions(opts)
+	o.Extra = extra
+	return nil
+}
+
+// jsonFieldNames returns the JSON field names of the fields of struct v.
+func jsonFieldNames(v interface{}) map[string]bool {
+	t := reflect.TypeOf(v)
+	names := make(map[string]bool, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if name != "" && name != "-" {
+			names[name] = true
+		}
+	}
+	return names
+}
+
+// marshalWithExtra marshals struct v, adding those fields of extra which v
+// does not model.
+func marshalWithExtra(v interface{}, extra map[string]interface{}) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil || len(extra) == 0 {
+		return data, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	known := jsonFieldNames(v)
+	for name, value := range extra {
+		if known[name] {
+			continue
+		}
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		fields[name] = raw
+	}
+	return json.Marshal(fields)
+}
+
+// unmodeledFields returns the fields of the JSON object data which struct v
+// does not model, or nil if there are none.
+func unmodeledFields(data []byte, v interface{}) (map[string]interface{}, error) {
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	for name := range jsonFieldNames(v) {
+		delete(fields, name)
+	}
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	return fields, nil
+}
+
+// Name returns the design document name, which is the ID without the
+// '_design/' prefix.
+func (d *DesignDoc) Name() string {
+	return strings.TrimPrefix(d.ID, DesignDocPrefix)
+}
+
+// DesignDocSyncOptions modify the behavior of SyncDesignDocs.
+type DesignDocSyncOptions struct {
+	// DryRun causes SyncDesignDocs to report the changes it would make,
+	// without making them.
+	DryRun bool
+	// WarmViews causes one view of each written design document to be
+	// queried after it is written, so that the view indexes are built before
+	// SyncDesignDocs returns, rather than on the first query by the
+	// application. Design documents with partitioned views are not warmed.
+	WarmViews bool
+}
+
+// DesignDocSyncReport describes the changes made by SyncDesignDocs. Each field
+// lists design document IDs.
+type DesignDocSyncReport struct {
+	Created   []string
+	Updated   []string
+	Unchanged []string
+}
+
+// SyncDesignDocs writes each of the design documents to the database, unless
+// an identical design document already exists. The Rev field of each design
+// document is ignored; the current revision is fetched from the database.
+// Design documents in the database which are not included in ddocs are left
+// alone.
+//
+// Fields of an existing design document which DesignDoc does not model, such
+// as autoupdate or indexes, are preserved, unless set in the Extra field of
+// the corresponding design document.
+func (db *DB) SyncDesignDocs(ctx context.Context, ddocs []DesignDoc, options DesignDocSyncOptions) (*DesignDocSyncReport, error) {
+	report := &DesignDocSyncReport{}
+	for _, ddoc := range ddocs {
+		if ddoc.Name() == "" {
+			return nil, missingArg("design doc ID")
+		}
+		ddoc.ID = DesignDocPrefix + ddoc.Name()
+		ddoc.Rev = ""
+		var current map[string]interface{}
+		err := db.Get(ctx, ddoc.ID).ScanDoc(&current)
+		if err != nil && StatusCode(err) != StatusNotFound {
+			return nil, err
+		}
+		var doc map[string]interface{}
+		if e := remarshal(&ddoc, &doc); e != nil {
+			return nil, errors.WrapStatus(StatusBadRequest, e)
+		}
+		if err == nil {
+			rev, _ := current["_rev"].(string)
+			delete(current, "_rev")
+			known := jsonFieldNames(designDoc{})
+			for name, value := range current {
+				if _, ok := doc[name]; !ok && !known[name] {
+					doc[name] = value
+				}
+			}
+			same, e := sameJSON(doc, current)
+			if e != nil {
+				return nil, e
+			}
+			if same {
+				report.Unchanged = append(report.Unchanged, ddoc.ID)
+				continue
+			}
+			doc["_rev"] = rev
+			report.Updated = append(report.Updated, ddoc.ID)
+		} else {
+			report.Created = append(report.Created, ddoc.ID)
+		}
+		if options.DryRun {
+			continue
+		}
+		if _, err := db.Put(ctx, ddoc.ID, doc); err != nil {
+			return nil, err
+		}
+		if options.WarmViews {
+			if err := db.warmViews(ctx, &ddoc); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return report, nil
+}
+
+// warmViews queries a single view of ddoc, which causes all of the design
+// document's view indexes to be built. Partitioned views cannot be queried
+// globally, and querying a single partition builds only part of the index, so
+// they are not warmed.
+func (db *DB) warmViews(ctx context.Context, ddoc *DesignDoc) error {
+	if len(ddoc.Views) == 0 {
+		return nil
+	}
+	partitioned, err := db.partitionedDesignDoc(ctx, ddoc)
+	if err != nil || partitioned {
+		return err
+	}
+	views := make([]string, 0, len(ddoc.Views))
+	for name := range ddoc.Views {
+		views = append(views, name)
+	}
+	sort.Strings(views)
+	rows, err := db.Query(ctx, ddoc.Name(), views[0], Options{"limit": 0})
+	if err != nil {
+		return err
+	}
+	return rows.Close()
+}
+
+// partitionedDesignDoc returns true if ddoc's views are partitioned, either
+// explicitly, or by default because the database is partitioned.
+func (db *DB) partitionedDesignDoc(ctx context.Context, ddoc *DesignDoc) (bool, error) {
+	if ddoc.Options != nil && ddoc.Options.Partitioned != nil {
+		return *ddoc.Options.Partitioned, nil
+	}
+	stats, err := db.Stats(ctx)
+	if err != nil {
+		return false, err
+	}
+	return stats.Partitioned, nil
+}
+
+// sameJSON returns true if a and b have the same JSON representation.
+func sameJSON(a, b interface{}) (bool, error) {
+	var x, y interface{}
+	if err := remarshal(a, &x); err != nil {
+		return false, errors.WrapStatus(StatusBadRequest, err)
+	}
+	if err := remarshal(b, &y); err != nil {
+		return false, errors.WrapStatus(StatusBadResponse, err)
+	}
+	return reflect.DeepEqual(x, y), nil
+}
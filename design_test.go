@@ -0,0 +1,178 @@
+package kivik
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/flimzy/diff"
+	"github.com/flimzy/testy"
+	"github.com/go-kivik/kivik/driver"
+	"github.com/go-kivik/kivik/errors"
+	"github.com/go-kivik/kivik/mock"
+)
+
+func TestSyncDesignDocs(t *testing.T) {
+	partitioned := true
+	existing := map[string]string{
+		"_design/same":    `{"_id":"_design/same","_rev":"1-abc","views":{"foo":{"map":"function(doc){}"}}}`,
+		"_design/changed": `{"_id":"_design/changed","_rev":"2-abc","views":{"foo":{"map":"function(doc){ emit(1) }"}}}`,
+		"_design/extra":   `{"_id":"_design/extra","_rev":"1-abc","autoupdate":false,"rewrites":[{"from":"/","to":"index.html"}],"views":{"foo":{"map":"function(doc){}","options":{"collation":"raw"}}}}`,
+		"_design/part":    `{"_id":"_design/part","_rev":"1-abc","autoupdate":false,"indexes":{"a":{"index":"function(doc){}"}},"views":{"foo":{"map":"function(doc){}"}}}`,
+	}
+	ddocs := []DesignDoc{
+		{ID: "_design/same", Views: map[string]View{"foo": {Map: "function(doc){}"}}},
+		{ID: "changed", Rev: "1-old", Views: map[string]View{"foo": {Map: "function(doc){}"}, "bar": {Map: "function(doc){}", Reduce: "_count"}}},
+		{ID: "_design/new", ValidateDocUpdate: "function(){}"},
+		{ID: "_design/extra", Views: map[string]View{"foo": {Map: "function(doc){}", Extra: map[string]interface{}{"options": map[string]interface{}{"collation": "raw"}}}}},
+		{ID: "_design/part", Extra: map[string]interface{}{"autoupdate": true}, Options: &DesignDocOptions{Partitioned: &partitioned}, Views: map[string]View{"foo": {Map: "function(doc){}"}}},
+	}
+	type put struct {
+		ID  string
+		Doc string
+	}
+	newDB := func(puts *[]put, queries *[]string) *mock.DB {
+		return &mock.DB{
+			GetFunc: func(_ context.Context, docID string, _ map[string]interface{}) (*driver.Document, error) {
+				doc, ok := existing[docID]
+				if !ok {
+					return nil, errors.Status(StatusNotFound, "missing")
+				}
+				return &driver.Document{Body: body(doc)}, nil
+			},
+			PutFunc: func(_ context.Context, docID string, doc interface{}, _ map[string]interface{}) (string, error) {
+				data, err := json.Marshal(doc)
+				if err != nil {
+					return "", err
+				}
+				*puts = append(*puts, put{docID, string(data)})
+				return "1-xxx", nil
+			},
+			QueryFunc: func(_ context.Context, ddoc, view string, opts map[string]interface{}) (driver.Rows, error) {
+				*queries = append(*queries, fmt.Sprintf("%s/%s %v", ddoc, view, opts))
+				return &mock.Rows{CloseFunc: func() error { return nil }}, nil
+			},
+			StatsFunc: func(_ context.Context) (*driver.DBStats, error) {
+				return &driver.DBStats{}, nil
+			},
+		}
+	}
+	expectedReport := &DesignDocSyncReport{
+		Created:   []string{"_design/new"},
+		Updated:   []string{"_design/changed", "_design/part"},
+		Unchanged: []string{"_design/same", "_design/extra"},
+	}
+	expectedPuts := []put{
+		{"_design/changed", `{"_id":"_design/changed","_rev":"2-abc","views":{"bar":{"map":"function(doc){}","reduce":"_count"},"foo":{"map":"function(doc){}"}}}`},
+		{"_design/new", `{"_id":"_design/new","validate_doc_update":"function(){}"}`},
+		{"_design/part", `{"_id":"_design/part","_rev":"1-abc","autoupdate":true,"indexes":{"a":{"index":"function(doc){}"}},"options":{"partitioned":true},"views":{"foo":{"map":"function(doc){}"}}}`},
+	}
+	tests := []struct {
+		name            string
+		db              driver.DB
+		ddocs           []DesignDoc
+		options         DesignDocSyncOptions
+		expected        *DesignDocSyncReport
+		expectedPuts    []put
+		expectedQueries []string
+		status          int
+		err             string
+	}{
+		{
+			name:   "missing ID",
+			ddocs:  []DesignDoc{{ID: "_design/"}},
+			status: StatusBadRequest,
+			err:    "kivik: design doc ID required",
+		},
+		{
+			name: "get error",
+			db: &mock.DB{
+				GetFunc: func(_ context.Context, _ string, _ map[string]interface{}) (*driver.Document, error) {
+					return nil, errors.Status(StatusForbidden, "get error")
+				},
+			},
+			ddocs:  ddocs,
+			status: StatusForbidden,
+			err:    "get error",
+		},
+		{
+			name:     "dry run",
+			ddocs:    ddocs,
+			options:  DesignDocSyncOptions{DryRun: true},
+			expected: expectedReport,
+		},
+		{
+			name:         "success",
+			ddocs:        ddocs,
+			expected:     expectedReport,
+			expectedPuts: expectedPuts,
+		},
+		{
+			name:            "warm views",
+			ddocs:           ddocs,
+			options:         DesignDocSyncOptions{WarmViews: true},
+			expected:        expectedReport,
+			expectedPuts:    expectedPuts,
+			expectedQueries: []string{"changed/bar map[limit:0]"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var puts []put
+			var queries []string
+			dbi := test.db
+			if dbi == nil {
+				dbi = newDB(&puts, &queries)
+			}
+			db := &DB{driverDB: dbi}
+			result, err := db.SyncDesignDocs(context.Background(), test.ddocs, test.options)
+			testy.StatusError(t, test.err, test.status, err)
+			if d := diff.Interface(test.expected, result); d != nil {
+				t.Error(d)
+			}
+			if d := diff.Interface(test.expectedPuts, puts); d != nil {
+				t.Errorf("Unexpected puts:\n%s", d)
+			}
+			if d := diff.Interface(test.expectedQueries, queries); d != nil {
+				t.Errorf("Unexpected queries:\n%s", d)
+			}
+		})
+	}
+}
+
+func TestDesignDocJSON(t *testing.T) {
+	input := `{"_id":"_design/foo","autoupdate":false,"options":{"local_seq":true,"other":1},"views":{"bar":{"map":"function(doc){}","options":{"collation":"raw"}}}}`
+	var ddoc DesignDoc
+	if err := json.Unmarshal([]byte(input), &ddoc); err != nil {
+		t.Fatal(err)
+	}
+	expected := DesignDoc{
+		ID:      "_design/foo",
+		Options: &DesignDocOptions{LocalSeq: true, Extra: map[string]interface{}{"other": 1}},
+		Views: map[string]View{
+			"bar": {Map: "function(doc){}", Extra: map[string]interface{}{"options": map[string]interface{}{"collation": "raw"}}},
+		},
+		Extra: map[string]interface{}{"autoupdate": false},
+	}
+	if d := diff.AsJSON(expected, ddoc); d != nil {
+		t.Error(d)
+	}
+	if ddoc.Extra["autoupdate"] != false || ddoc.Options.Extra["other"] != 1.0 {
+		t.Errorf("Unexpected extra fields: %v, %v", ddoc.Extra, ddoc.Options.Extra)
+	}
+	output, err := json.Marshal(ddoc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := diff.JSON([]byte(input), output); d != nil {
+		t.Error(d)
+	}
+}
+
+func TestDesignDocName(t *testing.T) {
+	ddoc := &DesignDoc{ID: "_design/foo"}
+	if name := ddoc.Name(); name != "foo" {
+		t.Errorf("Unexpected name: %s", name)
+	}
+}
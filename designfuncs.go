@@ -0,0 +1,123 @@
+package kivik
+
+import (
+	"context"
+	"io"
+	"strings"
+
+	"github.com/go-kivik/kivik/driver"
+	"github.com/go-kivik/kivik/errors"
+)
+
+var designFuncsNotImplemented = errors.Status(StatusNotImplemented, "kivik: driver does not support design functions")
+
+// FunctionResponse is the raw response of an update handler, show function or
+// list function.
+type FunctionResponse struct {
+	// StatusCode is the HTTP status code of the response.
+	StatusCode int
+	// ContentType is the MIME type of the response body.
+	ContentType string
+	// ContentLength records the size of the response body. The value -1
+	// indicates that the length is unknown.
+	ContentLength int64
+	// DocID is the ID of the document written by an update handler, if any.
+	DocID string
+	// Rev is the new revision of the document written by an update handler,
+	// if any.
+	Rev string
+	// Body is the response body. It is the caller's responsibility to close
+	// Body.
+	Body io.ReadCloser
+}
+
+// UpdateHandler calls an update handler function, and returns the raw
+// response. ddoc may or may not be prefixed with '_design/'. docID may be
+// empty, to call the handler without a document. body is sent as-is if it is
+// a string, []byte, json.RawMessage or io.Reader, which permits non-JSON
+// request bodies. Any other value is marshaled to JSON. A nil body sends an
+// empty request body.
+//
+// See http://docs.couchdb.org/en/2.1.1/api/ddoc/render.html#db-design-design-doc-update-update-name
+func (db *DB) UpdateHandler(ctx context.Context, ddoc, funcName, docID string, body interface{}, options ...Options) (*FunctionResponse, error) {
+	caller, ok := db.driverDB.(driver.DesignFuncCaller)
+	if !ok {
+		return nil, designFuncsNotImplemented
+	}
+	ddoc, err := validateDesignFunc(ddoc, funcName)
+	if err != nil {
+		return nil, err
+	}
+	opts, err := mergeOptions(options...)
+	if err != nil {
+		return nil, err
+	}
+	return newFunctionResponse(caller.UpdateHandler(ctx, ddoc, funcName, docID, body, opts))
+}
+
+// Show calls a show function, and returns the raw response. ddoc may or may
+// not be prefixed with '_design/'. docID may be empty, to call the function
+// without a document.
+//
+// See http://docs.couchdb.org/en/2.1.1/api/ddoc/render.html#db-design-design-doc-show-show-name
+func (db *DB) Show(ctx context.Context, ddoc, funcName, docID string, options ...Options) (*FunctionResponse, error) {
+	caller, ok := db.driverDB.(driver.DesignFuncCaller)
+	if !ok {
+		return nil, designFuncsNotImplemented
+	}
+	ddoc, err := validateDesignFunc(ddoc, funcName)
+	if err != nil {
+		return nil, err
+	}
+	opts, err := mergeOptions(options...)
+	if err != nil {
+		return nil, err
+	}
+	return newFunctionResponse(caller.Show(ctx, ddoc, funcName, docID, opts))
+}
+
+// List calls a list function against the results of view, and returns the
+// raw response. ddoc may or may not be prefixed with '_design/'. If the view
+// is defined in a different design document, view should be in the form
+// {ddoc}/{view}. Options are passed to the view, as with Query.
+//
+// See http://docs.couchdb.org/en/2.1.1/api/ddoc/render.html#db-design-design-doc-list-list-name-view-name
+func (db *DB) List(ctx context.Context, ddoc, funcName, view string, options ...Options) (*FunctionResponse, error) {
+	caller, ok := db.driverDB.(driver.DesignFuncCaller)
+	if !ok {
+		return nil, designFuncsNotImplemented
+	}
+	ddoc, err := validateDesignFunc(ddoc, funcName)
+	if err != nil {
+		return nil, err
+	}
+	if view == "" {
+		return nil, missingArg("view")
+	}
+	opts, err := mergeOptions(options...)
+	if err != nil {
+		return nil, err
+	}
+	return newFunctionResponse(caller.List(ctx, ddoc, funcName, strings.TrimPrefix(view, DesignDocPrefix), opts))
+}
+
+// validateDesignFunc returns ddoc without the '_design/' prefix, or an error
+// if either argument is empty.
+func validateDesignFunc(ddoc, funcName string) (string, error) {
+	ddoc = strings.TrimPrefix(ddoc, DesignDocPrefix)
+	if ddoc == "" {
+		return "", missingArg("ddoc")
+	}
+	if funcName == "" {
+		return "", missingArg("funcName")
+	}
+	return ddoc, nil
+}
+
+func newFunctionResponse(resp *driver.FunctionResponse, err error) (*FunctionResponse, error) {
+	if err != nil {
+		return nil, err
+	}
+	r := FunctionResponse(*resp)
+	return &r, nil
+}
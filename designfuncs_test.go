@@ -0,0 +1,234 @@
+package kivik
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/flimzy/diff"
+	"github.com/flimzy/testy"
+	"github.com/go-kivik/kivik/driver"
+	"github.com/go-kivik/kivik/mock"
+)
+
+func TestUpdateHandler(t *testing.T) {
+	tests := []struct {
+		name     string
+		db       driver.DB
+		ddoc     string
+		funcName string
+		docID    string
+		body     interface{}
+		options  Options
+		expected *FunctionResponse
+		status   int
+		err      string
+	}{
+		{
+			name:   "not implemented",
+			db:     &mock.DB{},
+			status: StatusNotImplemented,
+			err:    "kivik: driver does not support design functions",
+		},
+		{
+			name:     "missing ddoc",
+			db:       &mock.DesignFuncCaller{},
+			ddoc:     "_design/",
+			funcName: "foo",
+			status:   StatusBadRequest,
+			err:      "kivik: ddoc required",
+		},
+		{
+			name:   "missing func",
+			db:     &mock.DesignFuncCaller{},
+			ddoc:   "foo",
+			status: StatusBadRequest,
+			err:    "kivik: funcName required",
+		},
+		{
+			name: "db error",
+			db: &mock.DesignFuncCaller{
+				UpdateHandlerFunc: func(_ context.Context, _, _, _ string, _ interface{}, _ map[string]interface{}) (*driver.FunctionResponse, error) {
+					return nil, errors.New("update error")
+				},
+			},
+			ddoc:     "foo",
+			funcName: "bar",
+			status:   StatusInternalServerError,
+			err:      "update error",
+		},
+		{
+			name: "success",
+			db: &mock.DesignFuncCaller{
+				UpdateHandlerFunc: func(_ context.Context, ddoc, funcName, docID string, body interface{}, options map[string]interface{}) (*driver.FunctionResponse, error) {
+					if ddoc != "foo" || funcName != "bar" || docID != "baz" {
+						return nil, fmt.Errorf("Unexpected args: %s, %s, %s", ddoc, funcName, docID)
+					}
+					if body != "plain text" {
+						return nil, fmt.Errorf("Unexpected body: %v", body)
+					}
+					if d := diff.Interface(testOptions, options); d != nil {
+						return nil, fmt.Errorf("Unexpected options:\n%s", d)
+					}
+					return &driver.FunctionResponse{
+						StatusCode:  201,
+						ContentType: "text/plain",
+						DocID:       "baz",
+						Rev:         "2-xxx",
+					}, nil
+				},
+			},
+			ddoc:     "_design/foo",
+			funcName: "bar",
+			docID:    "baz",
+			body:     "plain text",
+			options:  testOptions,
+			expected: &FunctionResponse{
+				StatusCode:  201,
+				ContentType: "text/plain",
+				DocID:       "baz",
+				Rev:         "2-xxx",
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			db := &DB{driverDB: test.db}
+			result, err := db.UpdateHandler(context.Background(), test.ddoc, test.funcName, test.docID, test.body, test.options)
+			testy.StatusError(t, test.err, test.status, err)
+			if d := diff.Interface(test.expected, result); d != nil {
+				t.Error(d)
+			}
+		})
+	}
+}
+
+func TestShow(t *testing.T) {
+	tests := []struct {
+		name     string
+		db       driver.DB
+		ddoc     string
+		funcName string
+		docID    string
+		expected *FunctionResponse
+		status   int
+		err      string
+	}{
+		{
+			name:   "not implemented",
+			db:     &mock.DB{},
+			status: StatusNotImplemented,
+			err:    "kivik: driver does not support design functions",
+		},
+		{
+			name:   "missing func",
+			db:     &mock.DesignFuncCaller{},
+			ddoc:   "foo",
+			status: StatusBadRequest,
+			err:    "kivik: funcName required",
+		},
+		{
+			name: "db error",
+			db: &mock.DesignFuncCaller{
+				ShowFunc: func(_ context.Context, _, _, _ string, _ map[string]interface{}) (*driver.FunctionResponse, error) {
+					return nil, errors.New("show error")
+				},
+			},
+			ddoc:     "foo",
+			funcName: "bar",
+			status:   StatusInternalServerError,
+			err:      "show error",
+		},
+		{
+			name: "success",
+			db: &mock.DesignFuncCaller{
+				ShowFunc: func(_ context.Context, ddoc, funcName, docID string, _ map[string]interface{}) (*driver.FunctionResponse, error) {
+					if ddoc != "foo" || funcName != "bar" || docID != "" {
+						return nil, fmt.Errorf("Unexpected args: %s, %s, %s", ddoc, funcName, docID)
+					}
+					return &driver.FunctionResponse{StatusCode: 200, ContentType: "text/html"}, nil
+				},
+			},
+			ddoc:     "foo",
+			funcName: "bar",
+			expected: &FunctionResponse{StatusCode: 200, ContentType: "text/html"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			db := &DB{driverDB: test.db}
+			result, err := db.Show(context.Background(), test.ddoc, test.funcName, test.docID)
+			testy.StatusError(t, test.err, test.status, err)
+			if d := diff.Interface(test.expected, result); d != nil {
+				t.Error(d)
+			}
+		})
+	}
+}
+
+func TestList(t *testing.T) {
+	tests := []struct {
+		name     string
+		db       driver.DB
+		ddoc     string
+		funcName string
+		view     string
+		expected *FunctionResponse
+		status   int
+		err      string
+	}{
+		{
+			name:   "not implemented",
+			db:     &mock.DB{},
+			status: StatusNotImplemented,
+			err:    "kivik: driver does not support design functions",
+		},
+		{
+			name:     "missing view",
+			db:       &mock.DesignFuncCaller{},
+			ddoc:     "foo",
+			funcName: "bar",
+			status:   StatusBadRequest,
+			err:      "kivik: view required",
+		},
+		{
+			name: "db error",
+			db: &mock.DesignFuncCaller{
+				ListFunc: func(_ context.Context, _, _, _ string, _ map[string]interface{}) (*driver.FunctionResponse, error) {
+					return nil, errors.New("list error")
+				},
+			},
+			ddoc:     "foo",
+			funcName: "bar",
+			view:     "baz",
+			status:   StatusInternalServerError,
+			err:      "list error",
+		},
+		{
+			name: "view in other ddoc",
+			db: &mock.DesignFuncCaller{
+				ListFunc: func(_ context.Context, ddoc, funcName, view string, _ map[string]interface{}) (*driver.FunctionResponse, error) {
+					if ddoc != "foo" || funcName != "bar" || view != "other/baz" {
+						return nil, fmt.Errorf("Unexpected args: %s, %s, %s", ddoc, funcName, view)
+					}
+					return &driver.FunctionResponse{StatusCode: 200, ContentType: "text/csv"}, nil
+				},
+			},
+			ddoc:     "_design/foo",
+			funcName: "bar",
+			view:     "_design/other/baz",
+			expected: &FunctionResponse{StatusCode: 200, ContentType: "text/csv"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			db := &DB{driverDB: test.db}
+			result, err := db.List(context.Background(), test.ddoc, test.funcName, test.view)
+			testy.StatusError(t, test.err, test.status, err)
+			if d := diff.Interface(test.expected, result); d != nil {
+				t.Error(d)
+			}
+		})
+	}
+}
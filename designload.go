@@ -0,0 +1,199 @@
+package kivik
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/go-kivik/kivik/errors"
+)
+
+// LoadDesignDocs reads design document definitions from dir, for use with
+// SyncDesignDocs. Each file in dir named {name}.json must contain a complete
+// design document in JSON format. If the document has no _id, it is taken
+// from the file name.
+//
+// Each subdirectory of dir is read as a design document named after the
+// subdirectory, laid out as follows, where each file contains the source of a
+// single function. All files are optional.
+//
+//    {name}/language                 The design document language
+//    {name}/options.json             The design document options
+//    {name}/validate_doc_update.js   The validate_doc_update function
+//    {name}/views/{view}/map.js      A view's map function
+//    {name}/views/{view}/reduce.js   A view's reduce function
+//    {name}/filters/{filter}.js      A filter function
+//    {name}/updates/{update}.js      An update handler
+//    {name}/shows/{show}.js          A show function
+//    {name}/lists/{list}.js          A list function
+//
+// Other files are ignored. Design documents are returned sorted by ID.
+func LoadDesignDocs(dir string) ([]DesignDoc, error) {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, errors.WrapStatus(StatusBadRequest, err)
+	}
+	var ddocs []DesignDoc
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		var ddoc *DesignDoc
+		var err error
+		switch {
+		case entry.IsDir():
+			ddoc, err = loadDesignDocDir(path)
+		case filepath.Ext(entry.Name()) == ".json":
+			ddoc, err = loadDesignDocFile(path)
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		ddocs = append(ddocs, *ddoc)
+	}
+	sort.Sort(ddocsByID(ddocs))
+	return ddocs, nil
+}
+
+type ddocsByID []DesignDoc
+
+func (d ddocsByID) Len() int           { return len(d) }
+func (d ddocsByID) Less(i, j int) bool { return d[i].ID < d[j].ID }
+func (d ddocsByID) Swap(i, j int)      { d[i], d[j] = d[j], d[i] }
+
+func loadDesignDocFile(path string) (*DesignDoc, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.WrapStatus(StatusBadRequest, err)
+	}
+	ddoc := &DesignDoc{}
+	if err := json.Unmarshal(data, ddoc); err != nil {
+		return nil, errors.WrapStatus(StatusBadRequest, errors.Wrapf(err, "kivik: %s", path))
+	}
+	if ddoc.ID == "" {
+		ddoc.ID = strings.TrimSuffix(filepath.Base(path), ".json")
+	}
+	ddoc.ID = DesignDocPrefix + ddoc.Name()
+	return ddoc, nil
+}
+
+func loadDesignDocDir(dir string) (*DesignDoc, error) {
+	ddoc := &DesignDoc{ID: DesignDocPrefix + filepath.Base(dir)}
+	lang, err := readOptionalFile(filepath.Join(dir, "language"))
+	if err != nil {
+		return nil, err
+	}
+	ddoc.Language = strings.TrimSpace(lang)
+	options, err := readOptionalFile(filepath.Join(dir, "options.json"))
+	if err != nil {
+		return nil, err
+	}
+	if options != "" {
+		ddoc.Options = &DesignDocOptions{}
+		if err := json.Unmarshal([]byte(options), ddoc.Options); err != nil {
+			return nil, errors.WrapStatus(StatusBadRequest, errors.Wrapf(err, "kivik: %s", filepath.Join(dir, "options.json")))
+		}
+	}
+	if ddoc.ValidateDocUpdate, err = readOptionalFile(filepath.Join(dir, "validate_doc_update.js")); err != nil {
+		return nil, err
+	}
+	if ddoc.Views, err = loadViews(filepath.Join(dir, "views")); err != nil {
+		return nil, err
+	}
+	if ddoc.Filters, err = loadFunctions(filepath.Join(dir, "filters")); err != nil {
+		return nil, err
+	}
+	if ddoc.Updates, err = loadFunctions(filepath.Join(dir, "updates")); err != nil {
+		return nil, err
+	}
+	if ddoc.Shows, err = loadFunctions(filepath.Join(dir, "shows")); err != nil {
+		return nil, err
+	}
+	if ddoc.Lists, err = loadFunctions(filepath.Join(dir, "lists")); err != nil {
+		return nil, err
+	}
+	return ddoc, nil
+}
+
+// readOptionalFile returns the contents of the file, or an empty string if it
+// does not exist.
+func readOptionalFile(path string) (string, error) {
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", errors.WrapStatus(StatusBadRequest, err)
+	}
+	return string(data), nil
+}
+
+// readOptionalDir returns the entries in dir, or nil if it does not exist.
+func readOptionalDir(dir string) ([]os.FileInfo, error) {
+	entries, err := ioutil.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.WrapStatus(StatusBadRequest, err)
+	}
+	return entries, nil
+}
+
+func loadViews(dir string) (map[string]View, error) {
+	entries, err := readOptionalDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var views map[string]View
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		mapFn, err := readOptionalFile(filepath.Join(path, "map.js"))
+		if err != nil {
+			return nil, err
+		}
+		if mapFn == "" {
+			return nil, errors.Statusf(StatusBadRequest, "kivik: %s: map.js required", path)
+		}
+		reduceFn, err := readOptionalFile(filepath.Join(path, "reduce.js"))
+		if err != nil {
+			return nil, err
+		}
+		if views == nil {
+			views = make(map[string]View)
+		}
+		views[entry.Name()] = View{
+			Map:    mapFn,
+			Reduce: strings.TrimSpace(reduceFn),
+		}
+	}
+	return views, nil
+}
+
+func loadFunctions(dir string) (map[string]string, error) {
+	entries, err := readOptionalDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var funcs map[string]string
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".js" {
+			continue
+		}
+		data, err := ioutil.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, errors.WrapStatus(StatusBadRequest, err)
+		}
+		if funcs == nil {
+			funcs = make(map[string]string)
+		}
+		funcs[strings.TrimSuffix(entry.Name(), ".js")] = string(data)
+	}
+	return funcs, nil
+}
@@ -0,0 +1,112 @@
+package kivik
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/flimzy/diff"
+	"github.com/flimzy/testy"
+)
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestLoadDesignDocs(t *testing.T) {
+	partitioned := false
+	tests := []struct {
+		name     string
+		files    map[string]string
+		expected []DesignDoc
+		status   int
+		err      string
+	}{
+		{
+			name: "json files",
+			files: map[string]string{
+				"foo.json":   `{"views":{"a":{"map":"function(doc){}"}}}`,
+				"bar.json":   `{"_id":"_design/baz","language":"javascript"}`,
+				"README.txt": "ignored",
+			},
+			expected: []DesignDoc{
+				{ID: "_design/baz", Language: "javascript"},
+				{ID: "_design/foo", Views: map[string]View{"a": {Map: "function(doc){}"}}},
+			},
+		},
+		{
+			name: "invalid json",
+			files: map[string]string{
+				"foo.json": `{`,
+			},
+			status: StatusBadRequest,
+			err:    "^kivik: .*/foo.json: unexpected end of JSON input$",
+		},
+		{
+			name: "directory layout",
+			files: map[string]string{
+				"app/language":                "javascript\n",
+				"app/options.json":            `{"partitioned":false}`,
+				"app/validate_doc_update.js":  "function(){}",
+				"app/views/by_type/map.js":    "function(doc){ emit(doc.type) }",
+				"app/views/by_type/reduce.js": "_count\n",
+				"app/filters/important.js":    "function(doc){ return doc.important }",
+				"app/filters/notes.txt":       "ignored",
+				"app/shows/doc.js":            "function(doc){}",
+				"app/lists/all.js":            "function(){}",
+				"app/updates/touch.js":        "function(doc){}",
+			},
+			expected: []DesignDoc{
+				{
+					ID:                "_design/app",
+					Language:          "javascript",
+					Options:           &DesignDocOptions{Partitioned: &partitioned},
+					ValidateDocUpdate: "function(){}",
+					Views:             map[string]View{"by_type": {Map: "function(doc){ emit(doc.type) }", Reduce: "_count"}},
+					Filters:           map[string]string{"important": "function(doc){ return doc.important }"},
+					Shows:             map[string]string{"doc": "function(doc){}"},
+					Lists:             map[string]string{"all": "function(){}"},
+					Updates:           map[string]string{"touch": "function(doc){}"},
+				},
+			},
+		},
+		{
+			name: "missing map",
+			files: map[string]string{
+				"app/views/broken/reduce.js": "_count",
+			},
+			status: StatusBadRequest,
+			err:    "^kivik: .*/app/views/broken: map.js required$",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "kivik-ddocs-")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir) // nolint: errcheck
+			writeFiles(t, dir, test.files)
+			result, err := LoadDesignDocs(dir)
+			testy.StatusErrorRE(t, test.err, test.status, err)
+			if d := diff.Interface(test.expected, result); d != nil {
+				t.Error(d)
+			}
+		})
+	}
+	t.Run("missing dir", func(t *testing.T) {
+		_, err := LoadDesignDocs("/this/does/not/exist")
+		if StatusCode(err) != StatusBadRequest {
+			t.Errorf("Unexpected error: %s", err)
+		}
+	})
+}
@@ -0,0 +1,21 @@
+package driver
+
+import "context"
+
+// ClusterMembership is a copy of kivik.ClusterMembership.
+type ClusterMembership struct {
+	AllNodes     []string `json:"all_nodes"`
+	ClusterNodes []string `json:"cluster_nodes"`
+}
+
+// Cluster is an optional interface that may be implemented by a Client for
+// servers which support clustering, such as CouchDB 2.x.
+type Cluster interface {
+	// Membership returns the nodes known to the node handling the request.
+	Membership(ctx context.Context) (*ClusterMembership, error)
+	// ClusterStatus returns the current cluster setup state.
+	ClusterStatus(ctx context.Context, options map[string]interface{}) (string, error)
+	// ClusterSetup performs the cluster setup action, which will be marshaled
+	// to JSON and sent as the request body.
+	ClusterSetup(ctx context.Context, action interface{}) error
+}
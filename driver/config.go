@@ -0,0 +1,26 @@
+package driver
+
+import "context"
+
+// Config represents all the config sections.
+type Config map[string]ConfigSection
+
+// ConfigSection represents all key/value pairs for a section of configuration.
+type ConfigSection map[string]string
+
+// Configer is an optional interface that may be implemented by a Client to
+// allow access to reading and setting server configuration.
+type Configer interface {
+	// Config returns the complete configuration for the specified node.
+	Config(ctx context.Context, node string) (Config, error)
+	// ConfigSection returns the named section of the configuration for the
+	// specified node.
+	ConfigSection(ctx context.Context, node, section string) (ConfigSection, error)
+	// ConfigValue returns a single configuration value.
+	ConfigValue(ctx context.Context, node, section, key string) (string, error)
+	// SetConfigValue sets a configuration value, and returns the old value.
+	SetConfigValue(ctx context.Context, node, section, key, value string) (string, error)
+	// DeleteConfigValue deletes a configuration value, and returns the old
+	// value.
+	DeleteConfigValue(ctx context.Context, node, section, key string) (string, error)
+}
@@ -86,6 +86,16 @@ type ClientReplicator interface {
 
 // Authenticator is an optional interface that may be implemented by a Client
 // that supports authenitcated connections.
+//
+// Kivik passes its own authenticator types to the driver as *BasicAuth,
+// *CookieAuth, *ProxyAuth or *JWTAuth, which drivers should support where
+// the backend does. Other values are passed through unaltered, so drivers may
+// also accept their own authenticator types.
+//
+// Kivik re-authenticates with the same authenticator, and retries once, the
+// most common requests which fail with 401 Unauthorized. Drivers using
+// session-based authentication, such as CookieAuth, may additionally renew
+// the session themselves when it is known to have expired.
 type Authenticator interface {
 	// Authenticate attempts to authenticate the client using an authenticator.
 	// If the authenticator is not known to the client, an error should be
@@ -93,6 +103,37 @@ type Authenticator interface {
 	Authenticate(ctx context.Context, authenticator interface{}) error
 }
 
+// BasicAuth requests HTTP Basic authentication, with the credentials sent
+// with every request.
+type BasicAuth struct {
+	Username string
+	Password string
+}
+
+// CookieAuth requests cookie authentication. The driver should POST the
+// credentials to /_session, and send the resulting session cookie with
+// subsequent requests.
+type CookieAuth struct {
+	Username string
+	Password string
+}
+
+// ProxyAuth requests proxy authentication. The driver should send the
+// X-Auth-CouchDB-UserName header with Username, X-Auth-CouchDB-Roles with
+// Roles joined by commas, and, if Token is not empty, X-Auth-CouchDB-Token
+// with Token, which is already signed.
+type ProxyAuth struct {
+	Username string
+	Roles    []string
+	Token    string
+}
+
+// JWTAuth requests JSON Web Token authentication. The driver should send
+// Token in an 'Authorization: Bearer' header.
+type JWTAuth struct {
+	Token string
+}
+
 // DBStats contains database statistics..
 type DBStats struct {
 	Name           string `json:"db_name"`
@@ -102,7 +143,38 @@ type DBStats struct {
 	UpdateSeq      string `json:"update_seq"`
 	DiskSize       int64  `json:"disk_size"`
 	ActiveSize     int64  `json:"data_size"`
-	ExternalSize   int64  `json:"-"`
+	// ExternalSize, and the sizes.file and sizes.active values reported by
+	// CouchDB 2.x, which take precedence over disk_size and data_size, must
+	// be read from the nested sizes object by the driver.
+	ExternalSize int64          `json:"-"`
+	Cluster      *ClusterConfig `json:"cluster,omitempty"`
+	// PurgeSeq must be set by the driver, as CouchDB 1.x and 2.0-2.2 report
+	// purge_seq as an integer, and later versions as a string. Integers
+	// should be converted to their decimal representation.
+	PurgeSeq          string `json:"-"`
+	InstanceStartTime string `json:"instance_start_time"`
+	// Partitioned must be read from props.partitioned by the driver.
+	Partitioned bool `json:"-"`
+	// RawResponse is the raw response body as returned by the server.
+	RawResponse json.RawMessage `json:"-"`
+}
+
+// ClusterConfig is a copy of kivik.ClusterConfig.
+type ClusterConfig struct {
+	Replicas    int `json:"n"`
+	Shards      int `json:"q"`
+	ReadQuorum  int `json:"r"`
+	WriteQuorum int `json:"w"`
+}
+
+// DBsStatser is an optional interface that may be implemented by a Client to
+// return the statistics of multiple databases in a single request, such as
+// with POST /_dbs_info.
+type DBsStatser interface {
+	// DBsStats returns the statistics of each of the requested databases, in
+	// the same order. The entry for any database which does not exist should
+	// be nil.
+	DBsStats(ctx context.Context, dbNames []string) ([]*DBStats, error)
 }
 
 // Members represents the members of a database security document.
@@ -115,6 +187,46 @@ type Members struct {
 type Security struct {
 	Admins  Members `json:"admins"`
 	Members Members `json:"members"`
+	// Extra holds any fields other than admins and members, which are
+	// preserved when the document is read and written as JSON. Drivers which
+	// do not store the security document as JSON should preserve them.
+	Extra map[string]interface{} `json:"-"`
+}
+
+type security Security
+
+// MarshalJSON satisfies the json.Marshaler interface.
+func (s Security) MarshalJSON() ([]byte, error) {
+	if len(s.Extra) == 0 {
+		return json.Marshal(security(s))
+	}
+	fields := make(map[string]interface{}, len(s.Extra)+2)
+	for name, value := range s.Extra {
+		fields[name] = value
+	}
+	fields["admins"] = s.Admins
+	fields["members"] = s.Members
+	return json.Marshal(fields)
+}
+
+// UnmarshalJSON satisfies the json.Unmarshaler interface.
+func (s *Security) UnmarshalJSON(data []byte) error {
+	var sec security
+	if err := json.Unmarshal(data, &sec); err != nil {
+		return err
+	}
+	var extra map[string]interface{}
+	if err := json.Unmarshal(data, &extra); err != nil {
+		return err
+	}
+	delete(extra, "admins")
+	delete(extra, "members")
+	if len(extra) == 0 {
+		extra = nil
+	}
+	*s = Security(sec)
+	s.Extra = extra
+	return nil
 }
 
 // DB is a database handle.
@@ -148,7 +260,8 @@ type DB interface {
 	// the iterator will continue indefinitely, until Close is called.
 	Changes(ctx context.Context, options map[string]interface{}) (Changes, error)
 	// PutAttachment uploads an attachment to the specified document, returning
-	// the new revision.
+	// the new revision. If the server reports the digest of the stored
+	// attachment, the driver should set att.Digest to that value.
 	PutAttachment(ctx context.Context, docID, rev string, att *Attachment, options map[string]interface{}) (newRev string, err error)
 	// GetAttachment fetches an attachment for the associated document ID. rev
 	// may be an empty string to fetch the most recent document version.
@@ -174,16 +287,16 @@ type Document struct {
 	// format.
 	Body io.ReadCloser
 
-	// Attachments will be nil except when include_docs=true.
+	// Attachments will be nil except when attachments=true, and the driver
+	// streams attachments separately from the document body.
 	Attachments Attachments
 }
 
 // Attachments is an iterator over the attachments included in a document when
-// Get is called with `include_docs=true`.
+// Get is called with `attachments=true`.
 type Attachments interface {
 	// Next is called to pupulate att with the next attachment in the result
-	// set.
-	//
+	// set, including all available metadata.
 	//
 	// Next should return io.EOF when there are no more attachments.
 	Next(att *Attachment) error
@@ -316,3 +429,65 @@ type Flusher interface {
 type Copier interface {
 	Copy(ctx context.Context, targetID, sourceID string, options map[string]interface{}) (targetRev string, err error)
 }
+
+// FunctionResponse is the raw response of an update handler, show function or
+// list function.
+type FunctionResponse struct {
+	// StatusCode is the HTTP status code of the response.
+	StatusCode int
+	// ContentType is the MIME type of the response body.
+	ContentType string
+	// ContentLength is the size of the response body in bytes, or -1 if
+	// unknown.
+	ContentLength int64
+	// DocID is the ID of the document written by an update handler, if any.
+	DocID string
+	// Rev is the new revision of the document written by an update handler,
+	// if any.
+	Rev string
+	// Body is the response body.
+	Body io.ReadCloser
+}
+
+// DesignFuncCaller is an optional interface that may be implemented by a DB
+// to support calling update handlers, show functions and list functions
+// stored in design documents. In each case, ddoc will be the design doc name
+// without the '_design/' prefix.
+type DesignFuncCaller interface {
+	// UpdateHandler calls an update handler. docID may be empty, in which case
+	// the handler is called without a document. If body is a string, []byte,
+	// json.RawMessage or io.Reader, it should be sent as-is. A nil body should
+	// result in an empty request body. Any other type should be marshaled to
+	// JSON.
+	UpdateHandler(ctx context.Context, ddoc, funcName, docID string, body interface{}, options map[string]interface{}) (*FunctionResponse, error)
+	// Show calls a show function. docID may be empty, in which case the
+	// function is called without a document.
+	Show(ctx context.Context, ddoc, funcName, docID string, options map[string]interface{}) (*FunctionResponse, error)
+	// List calls a list function against the results of a view. view may be
+	// in the form {ddoc}/{view}, if the view is defined in a different design
+	// document from the list function.
+	List(ctx context.Context, ddoc, funcName, view string, options map[string]interface{}) (*FunctionResponse, error)
+}
+
+// MultipartPutter is an optional interface that may be implemented by a DB to
+// store a document and its attachments in a single request, such as a
+// multipart/related PUT.
+type MultipartPutter interface {
+	// PutMultipart stores doc along with atts, returning the new revision.
+	// doc will not contain an _attachments field; the driver is responsible
+	// for describing atts in the document as required by the transport.
+	// atts will be sorted by filename.
+	PutMultipart(ctx context.Context, docID string, doc map[string]interface{}, atts []*Attachment, options map[string]interface{}) (newRev string, err error)
+}
+
+// AttachmentRangeGetter is an optional interface that may be implemented by a
+// DB to fetch part of an attachment, such as with an HTTP Range request. If
+// not satisfied, GetAttachment will be used instead, and the unwanted bytes
+// discarded.
+type AttachmentRangeGetter interface {
+	// GetAttachmentRange fetches length bytes of an attachment's content,
+	// starting at offset. A negative length indicates the remainder of the
+	// content. The returned Attachment's Size should reflect the length of the
+	// returned range.
+	GetAttachmentRange(ctx context.Context, docID, rev, filename string, offset, length int64, options map[string]interface{}) (*Attachment, error)
+}
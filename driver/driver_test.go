@@ -0,0 +1,47 @@
+package driver
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/flimzy/diff"
+)
+
+func TestSecurityJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected Security
+	}{
+		{
+			name:     "modeled fields only",
+			input:    `{"admins":{"names":["bob"]},"members":{"roles":["staff"]}}`,
+			expected: Security{Admins: Members{Names: []string{"bob"}}, Members: Members{Roles: []string{"staff"}}},
+		},
+		{
+			name:  "extra fields",
+			input: `{"admins":{},"members":{},"couchdb_auth_only":true}`,
+			expected: Security{
+				Extra: map[string]interface{}{"couchdb_auth_only": true},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var sec Security
+			if err := json.Unmarshal([]byte(test.input), &sec); err != nil {
+				t.Fatal(err)
+			}
+			if d := diff.Interface(test.expected, sec); d != nil {
+				t.Error(d)
+			}
+			output, err := json.Marshal(sec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if d := diff.JSON([]byte(test.input), output); d != nil {
+				t.Error(d)
+			}
+		})
+	}
+}
@@ -0,0 +1,10 @@
+package driver
+
+import "context"
+
+// Pinger is an optional interface that may be implemented by a Client to
+// provide a lightweight check of server availability, such as GET /_up.
+type Pinger interface {
+	// Ping returns true if the server is up and ready to accept requests.
+	Ping(ctx context.Context) (bool, error)
+}
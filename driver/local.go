@@ -0,0 +1,24 @@
+package driver
+
+import "context"
+
+// LocalDocer is an optional interface which may be implemented by a DB to
+// provide explicit support for local (non-replicating) documents. In each
+// method, docID includes the "_local/" prefix.
+type LocalDocer interface {
+	// GetLocal fetches the requested local document.
+	GetLocal(ctx context.Context, docID string, options map[string]interface{}) (*Document, error)
+	// PutLocal creates or updates the local document, and returns the new
+	// revision.
+	PutLocal(ctx context.Context, docID string, doc interface{}, options map[string]interface{}) (rev string, err error)
+	// DeleteLocal deletes the local document, and returns the new revision.
+	DeleteLocal(ctx context.Context, docID, rev string, options map[string]interface{}) (newRev string, err error)
+}
+
+// LocalDocsLister is an optional interface which may be implemented by a DB
+// to list local documents, such as with GET /{db}/_local_docs.
+type LocalDocsLister interface {
+	// LocalDocs returns the local documents in the database, subject to the
+	// options provided, which are the same as for AllDocs.
+	LocalDocs(ctx context.Context, options map[string]interface{}) (Rows, error)
+}
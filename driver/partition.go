@@ -0,0 +1,34 @@
+package driver
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// PartitionStats is a copy of kivik.PartitionStats.
+type PartitionStats struct {
+	DBName          string
+	DocCount        int64
+	DeletedDocCount int64
+	Partition       string
+	ActiveSize      int64
+	ExternalSize    int64
+	RawResponse     json.RawMessage
+}
+
+// Partitioner is an optional interface which may be implemented by a DB to
+// support partitioned databases, as introduced in CouchDB 3.0. Each method
+// is scoped to the named partition, as with GET /{db}/_partition/{partition}.
+type Partitioner interface {
+	// PartitionAllDocs returns the documents in the partition.
+	PartitionAllDocs(ctx context.Context, partition string, options map[string]interface{}) (Rows, error)
+	// PartitionQuery queries a view, limited to the partition.
+	PartitionQuery(ctx context.Context, partition, ddoc, view string, options map[string]interface{}) (Rows, error)
+	// PartitionFind executes a Mango query, limited to the partition.
+	PartitionFind(ctx context.Context, partition string, query interface{}) (Rows, error)
+	// PartitionExplain returns the query plan for a Mango query, limited to
+	// the partition.
+	PartitionExplain(ctx context.Context, partition string, query interface{}) (*QueryPlan, error)
+	// PartitionStats returns statistics about the partition.
+	PartitionStats(ctx context.Context, partition string) (*PartitionStats, error)
+}
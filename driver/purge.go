@@ -0,0 +1,23 @@
+package driver
+
+import "context"
+
+// PurgeResult is a copy of kivik.PurgeResult.
+type PurgeResult struct {
+	Seq    int64               `json:"purge_seq"`
+	Purged map[string][]string `json:"purged"`
+}
+
+// Purger is an optional interface which may be implemented by a DB to support
+// purging documents.
+type Purger interface {
+	// Purge permanently removes the references to the requested document
+	// revisions, as a map of document IDs to revisions.
+	Purge(ctx context.Context, docRevMap map[string][]string) (*PurgeResult, error)
+	// PurgedInfosLimit returns the maximum number of historical purges
+	// retained by the database.
+	PurgedInfosLimit(ctx context.Context) (int, error)
+	// SetPurgedInfosLimit sets the maximum number of historical purges
+	// retained by the database.
+	SetPurgedInfosLimit(ctx context.Context, limit int) error
+}
@@ -66,3 +66,31 @@ type Bookmarker interface {
 	// usage: http://docs.couchdb.org/en/2.1.1/api/database/find.html#pagination
 	Bookmark() string
 }
+
+// QueryStats contains the execution statistics of a query, as returned by the
+// /_find endpoint when the execution_stats option is true.
+type QueryStats struct {
+	// TotalKeysExamined is the number of index keys examined.
+	TotalKeysExamined int64 `json:"total_keys_examined"`
+	// TotalDocsExamined is the number of documents fetched from the database
+	// or index.
+	TotalDocsExamined int64 `json:"total_docs_examined"`
+	// TotalQuorumDocsExamined is the number of documents fetched from the
+	// database using an out-of-band document fetch. This is only non-zero when
+	// read quorum > 1 is specified in the query parameters.
+	TotalQuorumDocsExamined int64 `json:"total_quorum_docs_examined"`
+	// ResultsReturned is the number of results returned from the query.
+	ResultsReturned int64 `json:"results_returned"`
+	// ExecutionTime is the total execution time in milliseconds, as measured
+	// by the database.
+	ExecutionTime float64 `json:"execution_time_ms"`
+}
+
+// QueryStatser is an optional interface that may be implemented by a Rows to
+// report query execution statistics. This is intended for use by the /_find
+// endpoint.
+type QueryStatser interface {
+	// QueryStats returns the execution statistics of the query, or nil if
+	// none were reported.
+	QueryStats() *QueryStats
+}
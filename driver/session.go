@@ -3,6 +3,7 @@ package driver
 import (
 	"context"
 	"encoding/json"
+	"time"
 )
 
 // Session is a copy of kivik.Session
@@ -23,6 +24,10 @@ type Session struct {
 	// RawResponse is the raw JSON response sent by the server, useful for
 	// custom backends which may provide additional fields.
 	RawResponse json.RawMessage
+	// Expires is the time at which the session expires, such as determined by
+	// the Max-Age or Expires attributes of a session cookie. It is the zero
+	// value if unknown, or if the session does not expire.
+	Expires time.Time
 }
 
 // Sessioner is an optional interface that a Client may satisfy to provide
@@ -31,3 +36,10 @@ type Sessioner interface {
 	// Session returns information about the authenticated user.
 	Session(ctx context.Context) (*Session, error)
 }
+
+// Logouter is an optional interface that a Client may satisfy to end an
+// authenticated session, such as with DELETE /_session.
+type Logouter interface {
+	// Logout ends the current session, and discards any stored credentials.
+	Logout(ctx context.Context) error
+}
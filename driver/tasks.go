@@ -0,0 +1,41 @@
+package driver
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// ActiveTask is a copy of kivik.ActiveTask.
+type ActiveTask struct {
+	Type           string
+	Database       string
+	Node           string
+	PID            string
+	Progress       int
+	ChangesDone    int64
+	TotalChanges   int64
+	DesignDocument string
+	DocID          string
+	ReplicationID  string
+	Source         string
+	Target         string
+	StartedOn      time.Time
+	UpdatedOn      time.Time
+	RawResponse    json.RawMessage
+}
+
+// ActiveTasker is an optional interface that may be implemented by a Client
+// to list the tasks running on the server.
+type ActiveTasker interface {
+	// ActiveTasks returns the list of running tasks.
+	ActiveTasks(ctx context.Context) ([]*ActiveTask, error)
+}
+
+// NodeStatser is an optional interface that may be implemented by a Client to
+// report the statistics of a server node.
+type NodeStatser interface {
+	// NodeStats returns the raw JSON statistics object for the specified
+	// node.
+	NodeStats(ctx context.Context, node string) (json.RawMessage, error)
+}
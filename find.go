@@ -2,6 +2,7 @@ package kivik
 
 import (
 	"context"
+	"encoding/json"
 
 	"github.com/go-kivik/kivik/driver"
 	"github.com/go-kivik/kivik/errors"
@@ -15,6 +16,9 @@ var findNotImplemented = errors.Status(StatusNotImplemented, "kivik: driver does
 func (db *DB) Find(ctx context.Context, query interface{}) (*Rows, error) {
 	if finder, ok := db.driverDB.(driver.Finder); ok {
 		rowsi, err := finder.Find(ctx, query)
+		if db.client.renewSession(ctx, err) {
+			rowsi, err = finder.Find(ctx, query)
+		}
 		if err != nil {
 			return nil, err
 		}
@@ -66,8 +70,9 @@ func (db *DB) GetIndexes(ctx context.Context) ([]Index, error) {
 // QueryPlan is the query execution plan for a query, as returned by the Explain
 // function.
 type QueryPlan struct {
-	DBName   string                 `json:"dbname"`
-	Index    map[string]interface{} `json:"index"`
+	DBName string `json:"dbname"`
+	// Index is the index chosen by the server to satisfy the query.
+	Index    Index                  `json:"index"`
 	Selector map[string]interface{} `json:"selector"`
 	Options  map[string]interface{} `json:"opts"`
 	Limit    int64                  `json:"limit"`
@@ -75,8 +80,84 @@ type QueryPlan struct {
 
 	// Fields is the list of fields to be returned in the result set, or
 	// an empty list if all fields are to be returned.
-	Fields []interface{}          `json:"fields"`
-	Range  map[string]interface{} `json:"range"`
+	Fields []interface{} `json:"fields"`
+	// Range is the range of index keys scanned to satisfy the query.
+	Range QueryRange `json:"range"`
+}
+
+// QueryRange is the range of index keys scanned by a query. For JSON indexes,
+// each key is an array with one element per indexed field.
+type QueryRange struct {
+	StartKey interface{} `json:"start_key"`
+	EndKey   interface{} `json:"end_key"`
+}
+
+// FullScan returns true if the query plan uses the special _all_docs index,
+// which means that every document in the database will be examined.
+func (p *QueryPlan) FullScan() bool {
+	return p.Index.Type == "special"
+}
+
+// Fields returns the names of the fields covered by the index, in index order.
+// Sort directions, if present in the definition, are discarded.
+func (i *Index) Fields() []string {
+	var def struct {
+		Fields []interface{} `json:"fields"`
+	}
+	if err := remarshal(i.Definition, &def); err != nil {
+		return nil
+	}
+	fields := make([]string, 0, len(def.Fields))
+	for _, field := range def.Fields {
+		switch t := field.(type) {
+		case string:
+			fields = append(fields, t)
+		case map[string]interface{}:
+			for name := range t {
+				fields = append(fields, name)
+			}
+		}
+	}
+	return fields
+}
+
+// remarshal converts from one JSON-compatible type to another. If from is a
+// string, []byte, or json.RawMessage, it is treated as a raw JSON payload.
+func remarshal(from, to interface{}) error {
+	var data []byte
+	switch t := from.(type) {
+	case string:
+		data = []byte(t)
+	case []byte:
+		data = t
+	case json.RawMessage:
+		data = t
+	default:
+		var err error
+		data, err = json.Marshal(from)
+		if err != nil {
+			return err
+		}
+	}
+	return json.Unmarshal(data, to)
+}
+
+func newQueryPlan(plan *driver.QueryPlan) (*QueryPlan, error) {
+	qp := &QueryPlan{
+		DBName:   plan.DBName,
+		Selector: plan.Selector,
+		Options:  plan.Options,
+		Limit:    plan.Limit,
+		Skip:     plan.Skip,
+		Fields:   plan.Fields,
+	}
+	if err := remarshal(plan.Index, &qp.Index); err != nil {
+		return nil, errors.WrapStatus(StatusBadResponse, err)
+	}
+	if err := remarshal(plan.Range, &qp.Range); err != nil {
+		return nil, errors.WrapStatus(StatusBadResponse, err)
+	}
+	return qp, nil
 }
 
 // Explain returns the query plan for a given query. Explain takes the same
@@ -87,8 +168,7 @@ func (db *DB) Explain(ctx context.Context, query interface{}) (*QueryPlan, error
 		if err != nil {
 			return nil, err
 		}
-		qp := QueryPlan(*plan)
-		return &qp, nil
+		return newQueryPlan(plan)
 	}
 	return nil, findNotImplemented
 }
@@ -2,6 +2,7 @@ package kivik
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"testing"
@@ -298,6 +299,51 @@ func TestExplain(t *testing.T) {
 			query:    int(3),
 			expected: &QueryPlan{DBName: "foo"},
 		},
+		{
+			name: "typed index and range",
+			db: &mock.Finder{
+				ExplainFunc: func(_ context.Context, _ interface{}) (*driver.QueryPlan, error) {
+					return &driver.QueryPlan{
+						DBName: "foo",
+						Index: map[string]interface{}{
+							"ddoc": "_design/idx",
+							"name": "by-name",
+							"type": "json",
+							"def":  map[string]interface{}{"fields": []interface{}{map[string]interface{}{"name": "asc"}}},
+						},
+						Range: map[string]interface{}{
+							"start_key": []interface{}{"a"},
+							"end_key":   []interface{}{"b"},
+						},
+					}, nil
+				},
+			},
+			expected: &QueryPlan{
+				DBName: "foo",
+				Index: Index{
+					DesignDoc:  "_design/idx",
+					Name:       "by-name",
+					Type:       "json",
+					Definition: map[string]interface{}{"fields": []interface{}{map[string]interface{}{"name": "asc"}}},
+				},
+				Range: QueryRange{
+					StartKey: []interface{}{"a"},
+					EndKey:   []interface{}{"b"},
+				},
+			},
+		},
+		{
+			name: "invalid index",
+			db: &mock.Finder{
+				ExplainFunc: func(_ context.Context, _ interface{}) (*driver.QueryPlan, error) {
+					return &driver.QueryPlan{
+						Index: map[string]interface{}{"name": 123},
+					}, nil
+				},
+			},
+			status: StatusBadResponse,
+			err:    "json: cannot unmarshal number into Go struct field Index.name of type string",
+		},
 	}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
@@ -310,3 +356,53 @@ func TestExplain(t *testing.T) {
 		})
 	}
 }
+
+func TestQueryPlanFullScan(t *testing.T) {
+	plan := &QueryPlan{Index: Index{Name: "_all_docs", Type: "special"}}
+	if !plan.FullScan() {
+		t.Errorf("Expected full scan for special index")
+	}
+	plan = &QueryPlan{Index: Index{Name: "foo", Type: "json"}}
+	if plan.FullScan() {
+		t.Errorf("Unexpected full scan for json index")
+	}
+}
+
+func TestIndexFields(t *testing.T) {
+	tests := []struct {
+		name     string
+		index    Index
+		expected []string
+	}{
+		{
+			name:     "no definition",
+			index:    Index{},
+			expected: []string{},
+		},
+		{
+			name:     "invalid definition",
+			index:    Index{Definition: map[string]interface{}{"fields": "foo"}},
+			expected: nil,
+		},
+		{
+			name: "mixed fields",
+			index: Index{Definition: map[string]interface{}{
+				"fields": []interface{}{"foo", map[string]interface{}{"bar": "desc"}},
+			}},
+			expected: []string{"foo", "bar"},
+		},
+		{
+			name:     "raw JSON definition",
+			index:    Index{Definition: json.RawMessage(`{"fields":[{"foo":"asc"}]}`)},
+			expected: []string{"foo"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := test.index.Fields()
+			if d := diff.Interface(test.expected, result); d != nil {
+				t.Error(d)
+			}
+		})
+	}
+}
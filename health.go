@@ -0,0 +1,113 @@
+package kivik
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/go-kivik/kivik/driver"
+	"github.com/go-kivik/kivik/errors"
+)
+
+// Ping returns true if the server is up and ready to accept requests. If the
+// driver supports it, a lightweight request such as GET /_up is used.
+// Otherwise, the server version is requested, and any successful response is
+// taken to mean that the server is up.
+//
+// A false return value with a nil error indicates that the server responded,
+// but reported that it is not ready.
+//
+// See http://docs.couchdb.org/en/2.1.1/api/server/common.html#up
+func (c *Client) Ping(ctx context.Context) (bool, error) {
+	if pinger, ok := c.driverClient.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	if _, err := c.driverClient.Version(ctx); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// ReadinessCheck is an http.Handler suitable for use as a readiness probe,
+// such as by Kubernetes. It responds with 200 OK if the server is up, the
+// client has an authenticated session (if RequireSession is set), and all of
+// DBs exist. Otherwise, it responds with 503 Service Unavailable. In either
+// case, the response body is a JSON object describing the result.
+type ReadinessCheck struct {
+	// Client is the client to check.
+	Client *Client
+	// DBs lists the databases which must exist.
+	DBs []string
+	// RequireSession, if true, requires that the client be authenticated as
+	// a named user.
+	RequireSession bool
+	// Timeout, if non-zero, limits the time allowed for all checks.
+	Timeout time.Duration
+}
+
+var _ http.Handler = &ReadinessCheck{}
+
+// ReadinessHandler returns a ReadinessCheck which requires an authenticated
+// session and that each of dbNames exist.
+func (c *Client) ReadinessHandler(dbNames ...string) *ReadinessCheck {
+	return &ReadinessCheck{
+		Client:         c,
+		DBs:            dbNames,
+		RequireSession: true,
+	}
+}
+
+// Check performs the readiness checks, returning the first failure.
+func (r *ReadinessCheck) Check(ctx context.Context) error {
+	up, err := r.Client.Ping(ctx)
+	if err != nil {
+		return err
+	}
+	if !up {
+		return errors.Status(StatusServiceUnavailable, "kivik: server is not ready")
+	}
+	if r.RequireSession {
+		session, err := r.Client.Session(ctx)
+		if err != nil {
+			return err
+		}
+		if session.Name == "" {
+			return errors.Status(StatusUnauthorized, "kivik: session not authenticated")
+		}
+	}
+	for _, dbName := range r.DBs {
+		exists, err := r.Client.DBExists(ctx, dbName)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return errors.Statusf(StatusNotFound, "kivik: database %s does not exist", dbName)
+		}
+	}
+	return nil
+}
+
+type readinessResponse struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+}
+
+// ServeHTTP satisfies the http.Handler interface.
+func (r *ReadinessCheck) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	if r.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.Timeout)
+		defer cancel()
+	}
+	status := http.StatusOK
+	resp := readinessResponse{OK: true}
+	if err := r.Check(ctx); err != nil {
+		status = http.StatusServiceUnavailable
+		resp = readinessResponse{Error: err.Error()}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,168 @@
+package kivik
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/flimzy/testy"
+	"github.com/go-kivik/kivik/driver"
+	"github.com/go-kivik/kivik/errors"
+	"github.com/go-kivik/kivik/mock"
+)
+
+func TestPing(t *testing.T) {
+	tests := []struct {
+		name     string
+		client   driver.Client
+		expected bool
+		status   int
+		err      string
+	}{
+		{
+			name: "pinger, up",
+			client: &mock.Pinger{
+				PingFunc: func(_ context.Context) (bool, error) {
+					return true, nil
+				},
+			},
+			expected: true,
+		},
+		{
+			name: "pinger, down",
+			client: &mock.Pinger{
+				PingFunc: func(_ context.Context) (bool, error) {
+					return false, nil
+				},
+			},
+			expected: false,
+		},
+		{
+			name: "version fallback, up",
+			client: &mock.Client{
+				VersionFunc: func(_ context.Context) (*driver.Version, error) {
+					return &driver.Version{Version: "2.1.1"}, nil
+				},
+			},
+			expected: true,
+		},
+		{
+			name: "version fallback, error",
+			client: &mock.Client{
+				VersionFunc: func(_ context.Context) (*driver.Version, error) {
+					return nil, errors.Status(StatusNetworkError, "connection refused")
+				},
+			},
+			status: StatusNetworkError,
+			err:    "connection refused",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := &Client{driverClient: test.client}
+			result, err := client.Ping(context.Background())
+			testy.StatusError(t, test.err, test.status, err)
+			if result != test.expected {
+				t.Errorf("Unexpected result: %t", result)
+			}
+		})
+	}
+}
+
+// healthClient mocks a driver supporting Ping, Session and DBExists.
+type healthClient struct {
+	*mock.Pinger
+	SessionFunc func(context.Context) (*driver.Session, error)
+}
+
+func (c *healthClient) Session(ctx context.Context) (*driver.Session, error) {
+	return c.SessionFunc(ctx)
+}
+
+func newHealthClient(up bool, user string, dbs ...string) *healthClient {
+	return &healthClient{
+		Pinger: &mock.Pinger{
+			Client: &mock.Client{
+				DBExistsFunc: func(_ context.Context, dbName string, _ map[string]interface{}) (bool, error) {
+					for _, db := range dbs {
+						if db == dbName {
+							return true, nil
+						}
+					}
+					return false, nil
+				},
+			},
+			PingFunc: func(_ context.Context) (bool, error) {
+				return up, nil
+			},
+		},
+		SessionFunc: func(_ context.Context) (*driver.Session, error) {
+			return &driver.Session{Name: user}, nil
+		},
+	}
+}
+
+func TestReadinessCheck(t *testing.T) {
+	tests := []struct {
+		name     string
+		client   driver.Client
+		dbs      []string
+		status   int
+		expected string
+	}{
+		{
+			name:     "ready",
+			client:   newHealthClient(true, "bob", "foo", "bar"),
+			dbs:      []string{"foo", "bar"},
+			status:   http.StatusOK,
+			expected: `{"ok":true}`,
+		},
+		{
+			name:     "server down",
+			client:   newHealthClient(false, "bob"),
+			status:   http.StatusServiceUnavailable,
+			expected: `{"ok":false,"error":"kivik: server is not ready"}`,
+		},
+		{
+			name:     "not authenticated",
+			client:   newHealthClient(true, ""),
+			status:   http.StatusServiceUnavailable,
+			expected: `{"ok":false,"error":"kivik: session not authenticated"}`,
+		},
+		{
+			name:     "missing db",
+			client:   newHealthClient(true, "bob", "foo"),
+			dbs:      []string{"foo", "bar"},
+			status:   http.StatusServiceUnavailable,
+			expected: `{"ok":false,"error":"kivik: database bar does not exist"}`,
+		},
+		{
+			name: "sessions not supported",
+			client: &mock.Pinger{
+				PingFunc: func(_ context.Context) (bool, error) {
+					return true, nil
+				},
+			},
+			status:   http.StatusServiceUnavailable,
+			expected: `{"ok":false,"error":"kivik: driver does not support sessions"}`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := &Client{driverClient: test.client}
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("GET", "/ready", nil)
+			client.ReadinessHandler(test.dbs...).ServeHTTP(w, r)
+			if w.Code != test.status {
+				t.Errorf("Unexpected status: %d", w.Code)
+			}
+			if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+				t.Errorf("Unexpected Content-Type: %s", ct)
+			}
+			if body := w.Body.String(); body != test.expected+"\n" {
+				t.Errorf("Unexpected body: %s", body)
+			}
+		})
+	}
+}
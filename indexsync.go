@@ -0,0 +1,165 @@
+package kivik
+
+import (
+	"context"
+	"reflect"
+	"strings"
+
+	"github.com/go-kivik/kivik/driver"
+	"github.com/go-kivik/kivik/errors"
+)
+
+// IndexSpec describes a desired Mango index, as passed to SyncIndexes.
+type IndexSpec struct {
+	// DesignDoc is the design document in which the index is stored, with or
+	// without the '_design/' prefix. If empty, any design document matches,
+	// and the server chooses one when the index is created.
+	DesignDoc string
+	// Name is the name of the index, which must be unique within the set of
+	// specs passed to SyncIndexes.
+	Name string
+	// Index is the index definition, in the same format accepted by
+	// CreateIndex. For example:
+	//
+	//  map[string]interface{}{"fields": []string{"type", "created"}}
+	Index interface{}
+}
+
+// SyncOptions modify the behavior of SyncIndexes.
+type SyncOptions struct {
+	// DeleteUnmanaged causes any index not described by a spec to be deleted.
+	// The special _all_docs index is never deleted.
+	DeleteUnmanaged bool
+	// DryRun causes SyncIndexes to report the changes it would make, without
+	// making them.
+	DryRun bool
+}
+
+// IndexSyncReport describes the changes made by SyncIndexes.
+type IndexSyncReport struct {
+	// Created lists the specs for which no index existed.
+	Created []IndexSpec
+	// Updated lists the specs whose existing index had a different
+	// definition, and was replaced.
+	Updated []IndexSpec
+	// Unchanged lists the specs whose index already matched.
+	Unchanged []IndexSpec
+	// Deleted lists the unmanaged indexes which were deleted.
+	Deleted []Index
+}
+
+// Changed returns true if any index was, or in dry-run mode would be, created,
+// updated or deleted.
+func (r *IndexSyncReport) Changed() bool {
+	return len(r.Created)+len(r.Updated)+len(r.Deleted) > 0
+}
+
+// SyncIndexes brings the Mango indexes of the database in line with specs.
+// Indexes which don't exist are created. Indexes whose definition differs
+// from the spec are deleted and re-created. If options.DeleteUnmanaged is
+// true, indexes not matched by any spec are deleted.
+//
+// Indexes are matched by name and, if the spec includes one, design document.
+// Definitions are compared after normalizing sort directions, so that
+// {"fields": ["foo"]} matches {"fields": [{"foo": "asc"}]}, as returned by
+// GetIndexes.
+func (db *DB) SyncIndexes(ctx context.Context, specs []IndexSpec, options SyncOptions) (*IndexSyncReport, error) {
+	finder, ok := db.driverDB.(driver.Finder)
+	if !ok {
+		return nil, findNotImplemented
+	}
+	wanted := make([]map[string]interface{}, len(specs))
+	names := make(map[string]bool, len(specs))
+	for i, spec := range specs {
+		if spec.Name == "" {
+			return nil, missingArg("index name")
+		}
+		if names[spec.Name] {
+			return nil, errors.Statusf(StatusBadRequest, "kivik: duplicate index name '%s'", spec.Name)
+		}
+		names[spec.Name] = true
+		def, err := normalizeIndexDef(spec.Index)
+		if err != nil {
+			return nil, err
+		}
+		wanted[i] = def
+	}
+	existing, err := db.GetIndexes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	report := &IndexSyncReport{}
+	managed := make(map[int]bool)
+	for i, spec := range specs {
+		j := findIndex(existing, spec)
+		if j < 0 {
+			report.Created = append(report.Created, spec)
+			if !options.DryRun {
+				if err := finder.CreateIndex(ctx, spec.DesignDoc, spec.Name, spec.Index); err != nil {
+					return nil, err
+				}
+			}
+			continue
+		}
+		managed[j] = true
+		if have, err := normalizeIndexDef(existing[j].Definition); err == nil && reflect.DeepEqual(have, wanted[i]) {
+			report.Unchanged = append(report.Unchanged, spec)
+			continue
+		}
+		report.Updated = append(report.Updated, spec)
+		if options.DryRun {
+			continue
+		}
+		if err := finder.DeleteIndex(ctx, existing[j].DesignDoc, existing[j].Name); err != nil {
+			return nil, err
+		}
+		if err := finder.CreateIndex(ctx, spec.DesignDoc, spec.Name, spec.Index); err != nil {
+			return nil, err
+		}
+	}
+	if !options.DeleteUnmanaged {
+		return report, nil
+	}
+	for i, index := range existing {
+		if managed[i] || index.Type == "special" {
+			continue
+		}
+		report.Deleted = append(report.Deleted, index)
+		if !options.DryRun {
+			if err := finder.DeleteIndex(ctx, index.DesignDoc, index.Name); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return report, nil
+}
+
+// findIndex returns the position of the index matching spec, or -1.
+func findIndex(indexes []Index, spec IndexSpec) int {
+	ddoc := strings.TrimPrefix(spec.DesignDoc, "_design/")
+	for i, index := range indexes {
+		if index.Name != spec.Name {
+			continue
+		}
+		if ddoc == "" || ddoc == strings.TrimPrefix(index.DesignDoc, "_design/") {
+			return i
+		}
+	}
+	return -1
+}
+
+// normalizeIndexDef converts an index definition to a generic map, with each
+// field expressed as {"name": "direction"}.
+func normalizeIndexDef(index interface{}) (map[string]interface{}, error) {
+	var def map[string]interface{}
+	if err := remarshal(index, &def); err != nil {
+		return nil, errors.WrapStatus(StatusBadRequest, err)
+	}
+	fields, _ := def["fields"].([]interface{})
+	for i, field := range fields {
+		if name, ok := field.(string); ok {
+			fields[i] = map[string]interface{}{name: "asc"}
+		}
+	}
+	return def, nil
+}
@@ -0,0 +1,166 @@
+package kivik
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/flimzy/diff"
+	"github.com/flimzy/testy"
+	"github.com/go-kivik/kivik/driver"
+	"github.com/go-kivik/kivik/mock"
+)
+
+func TestSyncIndexes(t *testing.T) {
+	existing := []driver.Index{
+		{Name: "_all_docs", Type: "special", Definition: map[string]interface{}{"fields": []interface{}{map[string]interface{}{"_id": "asc"}}}},
+		{DesignDoc: "_design/app", Name: "by-type", Type: "json", Definition: map[string]interface{}{"fields": []interface{}{map[string]interface{}{"type": "asc"}}}},
+		{DesignDoc: "_design/app", Name: "by-date", Type: "json", Definition: map[string]interface{}{"fields": []interface{}{map[string]interface{}{"date": "asc"}}}},
+		{DesignDoc: "_design/old", Name: "stale", Type: "json", Definition: map[string]interface{}{"fields": []interface{}{map[string]interface{}{"foo": "asc"}}}},
+	}
+	specs := []IndexSpec{
+		{DesignDoc: "app", Name: "by-type", Index: map[string]interface{}{"fields": []string{"type"}}},
+		{Name: "by-date", Index: `{"fields":[{"date":"desc"}]}`},
+		{DesignDoc: "app", Name: "by-name", Index: map[string]interface{}{"fields": []string{"name"}}},
+	}
+	type call struct {
+		Method, DDoc, Name string
+	}
+	newFinder := func(calls *[]call) *mock.Finder {
+		return &mock.Finder{
+			GetIndexesFunc: func(_ context.Context) ([]driver.Index, error) {
+				return existing, nil
+			},
+			CreateIndexFunc: func(_ context.Context, ddoc, name string, _ interface{}) error {
+				*calls = append(*calls, call{"create", ddoc, name})
+				return nil
+			},
+			DeleteIndexFunc: func(_ context.Context, ddoc, name string) error {
+				*calls = append(*calls, call{"delete", ddoc, name})
+				return nil
+			},
+		}
+	}
+	expectedReport := &IndexSyncReport{
+		Created:   []IndexSpec{specs[2]},
+		Updated:   []IndexSpec{specs[1]},
+		Unchanged: []IndexSpec{specs[0]},
+	}
+	fullReport := &IndexSyncReport{
+		Created:   []IndexSpec{specs[2]},
+		Updated:   []IndexSpec{specs[1]},
+		Unchanged: []IndexSpec{specs[0]},
+		Deleted:   []Index{Index(existing[3])},
+	}
+	tests := []struct {
+		name          string
+		db            driver.DB
+		specs         []IndexSpec
+		options       SyncOptions
+		expected      *IndexSyncReport
+		expectedCalls []call
+		status        int
+		err           string
+	}{
+		{
+			name:   "non-finder",
+			db:     &mock.DB{},
+			status: StatusNotImplemented,
+			err:    "kivik: driver does not support Find interface",
+		},
+		{
+			name:   "missing name",
+			db:     &mock.Finder{},
+			specs:  []IndexSpec{{Index: map[string]interface{}{}}},
+			status: StatusBadRequest,
+			err:    "kivik: index name required",
+		},
+		{
+			name:   "duplicate name",
+			db:     &mock.Finder{},
+			specs:  []IndexSpec{{Name: "foo"}, {Name: "foo"}},
+			status: StatusBadRequest,
+			err:    "kivik: duplicate index name 'foo'",
+		},
+		{
+			name:   "invalid definition",
+			db:     &mock.Finder{},
+			specs:  []IndexSpec{{Name: "foo", Index: "oink"}},
+			status: StatusBadRequest,
+			err:    "invalid character 'o' looking for beginning of value",
+		},
+		{
+			name: "GetIndexes error",
+			db: &mock.Finder{
+				GetIndexesFunc: func(_ context.Context) ([]driver.Index, error) {
+					return nil, errors.New("get error")
+				},
+			},
+			status: StatusInternalServerError,
+			err:    "get error",
+		},
+		{
+			name: "create error",
+			db: &mock.Finder{
+				GetIndexesFunc: func(_ context.Context) ([]driver.Index, error) {
+					return nil, nil
+				},
+				CreateIndexFunc: func(_ context.Context, _, _ string, _ interface{}) error {
+					return errors.New("create error")
+				},
+			},
+			specs:  specs,
+			status: StatusInternalServerError,
+			err:    "create error",
+		},
+		{
+			name:     "dry run",
+			specs:    specs,
+			options:  SyncOptions{DryRun: true, DeleteUnmanaged: true},
+			expected: fullReport,
+		},
+		{
+			name:     "keep unmanaged",
+			specs:    specs,
+			expected: expectedReport,
+			expectedCalls: []call{
+				{"delete", "_design/app", "by-date"},
+				{"create", "", "by-date"},
+				{"create", "app", "by-name"},
+			},
+		},
+		{
+			name:     "delete unmanaged",
+			specs:    specs,
+			options:  SyncOptions{DeleteUnmanaged: true},
+			expected: fullReport,
+			expectedCalls: []call{
+				{"delete", "_design/app", "by-date"},
+				{"create", "", "by-date"},
+				{"create", "app", "by-name"},
+				{"delete", "_design/old", "stale"},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var calls []call
+			dbi := test.db
+			if dbi == nil {
+				dbi = newFinder(&calls)
+			}
+			db := &DB{driverDB: dbi}
+			result, err := db.SyncIndexes(context.Background(), test.specs, test.options)
+			testy.StatusError(t, test.err, test.status, err)
+			if d := diff.Interface(test.expected, result); d != nil {
+				t.Error(d)
+			}
+			if d := diff.Interface(test.expectedCalls, calls); d != nil {
+				t.Errorf("Unexpected calls:\n%s", d)
+			}
+			if !result.Changed() {
+				t.Errorf("Expected changes to be reported")
+			}
+		})
+	}
+}
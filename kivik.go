@@ -3,6 +3,8 @@ package kivik
 import (
 	"context"
 	"encoding/json"
+	"sync"
+	"time"
 
 	"github.com/imdario/mergo"
 
@@ -15,6 +17,15 @@ type Client struct {
 	dsn          string
 	driverName   string
 	driverClient driver.Client
+
+	// authMu protects auth and sessionExpires.
+	authMu sync.Mutex
+	// auth is the authenticator most recently passed to Authenticate, used
+	// to renew the session.
+	auth interface{}
+	// sessionExpires is the expiry time of the session, as last reported by
+	// the driver.
+	sessionExpires time.Time
 }
 
 // Options is a collection of options. The keys and values are backend specific.
@@ -102,13 +113,18 @@ func (c *Client) DB(ctx context.Context, dbName string, options ...Options) (*DB
 	}, err
 }
 
-// AllDBs returns a list of all databases.
+// AllDBs returns a list of all databases. See AllDBsOptions for the supported
+// options.
 func (c *Client) AllDBs(ctx context.Context, options ...Options) ([]string, error) {
 	opts, err := mergeOptions(options...)
 	if err != nil {
 		return nil, err
 	}
-	return c.driverClient.AllDBs(ctx, opts)
+	dbs, err := c.driverClient.AllDBs(ctx, opts)
+	if c.renewSession(ctx, err) {
+		dbs, err = c.driverClient.AllDBs(ctx, opts)
+	}
+	return dbs, err
 }
 
 // DBExists returns true if the specified database exists.
@@ -117,7 +133,11 @@ func (c *Client) DBExists(ctx context.Context, dbName string, options ...Options
 	if err != nil {
 		return false, err
 	}
-	return c.driverClient.DBExists(ctx, dbName, opts)
+	exists, err := c.driverClient.DBExists(ctx, dbName, opts)
+	if c.renewSession(ctx, err) {
+		exists, err = c.driverClient.DBExists(ctx, dbName, opts)
+	}
+	return exists, err
 }
 
 // CreateDB creates a DB of the requested name.
@@ -126,7 +146,11 @@ func (c *Client) CreateDB(ctx context.Context, dbName string, options ...Options
 	if err != nil {
 		return nil, err
 	}
-	if e := c.driverClient.CreateDB(ctx, dbName, opts); e != nil {
+	e := c.driverClient.CreateDB(ctx, dbName, opts)
+	if c.renewSession(ctx, e) {
+		e = c.driverClient.CreateDB(ctx, dbName, opts)
+	}
+	if e != nil {
 		return nil, e
 	}
 	return c.DB(ctx, dbName, nil)
@@ -138,17 +162,37 @@ func (c *Client) DestroyDB(ctx context.Context, dbName string, options ...Option
 	if err != nil {
 		return err
 	}
-	return c.driverClient.DestroyDB(ctx, dbName, opts)
+	err = c.driverClient.DestroyDB(ctx, dbName, opts)
+	if c.renewSession(ctx, err) {
+		err = c.driverClient.DestroyDB(ctx, dbName, opts)
+	}
+	return err
 }
 
 // Authenticate authenticates the client with the passed authenticator, which
-// is driver-specific. If the driver does not understand the authenticator, an
-// error will be returned.
+// may be one of BasicAuth, CookieAuth, ProxyAuth or JWTAuth, or a pointer to
+// one, or a driver-specific authenticator. If the driver does not understand
+// the authenticator, an error will be returned.
+//
+// On success, the authenticator is retained, so that an expired session may
+// be renewed. If one of AllDBs, DBExists, CreateDB, DestroyDB, or the DB
+// methods AllDocs, Query, Get, CreateDoc, Put, Delete, Stats, GetAttachment,
+// Find or Changes fails with StatusUnauthorized, the client is
+// re-authenticated, and the request retried once. Requests whose body is
+// read from an io.Reader are not retried. See also Session.
 func (c *Client) Authenticate(ctx context.Context, a interface{}) error {
-	if auth, ok := c.driverClient.(driver.Authenticator); ok {
-		return auth.Authenticate(ctx, a)
+	auth, ok := c.driverClient.(driver.Authenticator)
+	if !ok {
+		return errors.Status(StatusNotImplemented, "kivik: driver does not support authentication")
+	}
+	if err := auth.Authenticate(ctx, driverAuthenticator(a)); err != nil {
+		return err
 	}
-	return errors.Status(StatusNotImplemented, "kivik: driver does not support authentication")
+	c.authMu.Lock()
+	c.auth = a
+	c.sessionExpires = time.Time{}
+	c.authMu.Unlock()
+	return nil
 }
 
 func missingArg(arg string) error {
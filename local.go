@@ -0,0 +1,124 @@
+package kivik
+
+import (
+	"context"
+	"strings"
+
+	"github.com/go-kivik/kivik/driver"
+	"github.com/go-kivik/kivik/errors"
+)
+
+// LocalPrefix is the prefix of local (non-replicating) document IDs.
+const LocalPrefix = "_local/"
+
+// localDocID returns docID with the local prefix, adding it if necessary.
+func localDocID(docID string) (string, error) {
+	if docID == "" || docID == LocalPrefix {
+		return "", missingArg("docID")
+	}
+	if strings.HasPrefix(docID, LocalPrefix) {
+		return docID, nil
+	}
+	return LocalPrefix + docID, nil
+}
+
+// GetLocal fetches the requested local document. docID may be given with or
+// without the "_local/" prefix. Any errors are deferred until the
+// row.ScanDoc call.
+//
+// See http://docs.couchdb.org/en/2.1.1/api/local.html#get--db-_local-docid
+func (db *DB) GetLocal(ctx context.Context, docID string, options ...Options) *Row {
+	id, err := localDocID(docID)
+	if err != nil {
+		return &Row{Err: err}
+	}
+	local, ok := db.driverDB.(driver.LocalDocer)
+	if !ok {
+		return db.Get(ctx, id, options...)
+	}
+	opts, err := mergeOptions(options...)
+	if err != nil {
+		return &Row{Err: err}
+	}
+	doc, err := local.GetLocal(ctx, id, opts)
+	if err != nil {
+		return &Row{Err: err}
+	}
+	return &Row{
+		ContentLength: doc.ContentLength,
+		Rev:           doc.Rev,
+		Body:          doc.Body,
+	}
+}
+
+// PutLocal creates or updates a local document, and returns the new revision.
+// docID may be given with or without the "_local/" prefix. Local documents are
+// not replicated, and have no revision history, making them suitable for
+// storing checkpoints.
+//
+// See http://docs.couchdb.org/en/2.1.1/api/local.html#put--db-_local-docid
+func (db *DB) PutLocal(ctx context.Context, docID string, doc interface{}, options ...Options) (rev string, err error) {
+	id, err := localDocID(docID)
+	if err != nil {
+		return "", err
+	}
+	local, ok := db.driverDB.(driver.LocalDocer)
+	if !ok {
+		return db.Put(ctx, id, doc, options...)
+	}
+	i, err := normalizeFromJSON(doc)
+	if err != nil {
+		return "", err
+	}
+	opts, err := mergeOptions(options...)
+	if err != nil {
+		return "", err
+	}
+	return local.PutLocal(ctx, id, i, opts)
+}
+
+// DeleteLocal deletes a local document. docID may be given with or without
+// the "_local/" prefix.
+//
+// See http://docs.couchdb.org/en/2.1.1/api/local.html#delete--db-_local-docid
+func (db *DB) DeleteLocal(ctx context.Context, docID, rev string, options ...Options) (newRev string, err error) {
+	id, err := localDocID(docID)
+	if err != nil {
+		return "", err
+	}
+	local, ok := db.driverDB.(driver.LocalDocer)
+	if !ok {
+		return db.Delete(ctx, id, rev, options...)
+	}
+	opts, err := mergeOptions(options...)
+	if err != nil {
+		return "", err
+	}
+	return local.DeleteLocal(ctx, id, rev, opts)
+}
+
+// LocalDocs returns a list of the local documents in the database. It accepts
+// the same options as AllDocs.
+//
+// Unlike the other local document methods, LocalDocs has no fallback, as
+// local documents are excluded from AllDocs and Changes, so cannot be listed
+// by other means. If the driver does not support listing local documents,
+// StatusNotImplemented is returned. This includes servers prior to CouchDB
+// 2.2, which lack the _local_docs endpoint.
+//
+// See http://docs.couchdb.org/en/2.2.0/api/database/bulk-api.html#get--db-_local_docs
+func (db *DB) LocalDocs(ctx context.Context, options ...Options) (*Rows, error) {
+	lister, ok := db.driverDB.(driver.LocalDocsLister)
+	if !ok {
+		return nil, errors.Status(StatusNotImplemented, "kivik: driver does not support local docs listing")
+	}
+	opts, err := mergeOptions(options...)
+	if err != nil {
+		return nil, err
+	}
+	rowsi, err := lister.LocalDocs(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return newRows(ctx, rowsi), nil
+}
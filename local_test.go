@@ -0,0 +1,205 @@
+package kivik
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/flimzy/diff"
+	"github.com/flimzy/testy"
+	"github.com/go-kivik/kivik/driver"
+	"github.com/go-kivik/kivik/errors"
+	"github.com/go-kivik/kivik/mock"
+)
+
+func TestGetLocal(t *testing.T) {
+	get := func(_ context.Context, docID string, _ map[string]interface{}) (*driver.Document, error) {
+		if docID != "_local/foo" {
+			return nil, errors.Status(StatusNotFound, "missing")
+		}
+		return &driver.Document{Rev: "0-1", Body: body(`{"_id":"_local/foo","seq":3}`)}, nil
+	}
+	tests := []struct {
+		name     string
+		db       driver.DB
+		docID    string
+		expected map[string]interface{}
+		status   int
+		err      string
+	}{
+		{
+			name:   "missing doc id",
+			db:     &mock.DB{},
+			docID:  "_local/",
+			status: StatusBadRequest,
+			err:    "kivik: docID required",
+		},
+		{
+			name:     "emulated",
+			db:       &mock.DB{GetFunc: get},
+			docID:    "foo",
+			expected: map[string]interface{}{"_id": "_local/foo", "seq": 3.0},
+		},
+		{
+			name:     "driver support",
+			db:       &mock.LocalDocer{GetLocalFunc: get},
+			docID:    "_local/foo",
+			expected: map[string]interface{}{"_id": "_local/foo", "seq": 3.0},
+		},
+		{
+			name:   "not found",
+			db:     &mock.LocalDocer{GetLocalFunc: get},
+			docID:  "bar",
+			status: StatusNotFound,
+			err:    "missing",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			db := &DB{driverDB: test.db}
+			var result map[string]interface{}
+			err := db.GetLocal(context.Background(), test.docID).ScanDoc(&result)
+			testy.StatusError(t, test.err, test.status, err)
+			if d := diff.Interface(test.expected, result); d != nil {
+				t.Error(d)
+			}
+		})
+	}
+}
+
+func TestPutLocal(t *testing.T) {
+	put := func(_ context.Context, docID string, doc interface{}, _ map[string]interface{}) (string, error) {
+		if d := diff.AsJSON(map[string]interface{}{"seq": 3}, doc); d != nil {
+			return "", fmt.Errorf("Unexpected doc:\n%s", d)
+		}
+		return "0-1 " + docID, nil
+	}
+	tests := []struct {
+		name     string
+		db       driver.DB
+		docID    string
+		expected string
+		status   int
+		err      string
+	}{
+		{
+			name:   "missing doc id",
+			db:     &mock.DB{},
+			status: StatusBadRequest,
+			err:    "kivik: docID required",
+		},
+		{
+			name:     "emulated",
+			db:       &mock.DB{PutFunc: put},
+			docID:    "foo",
+			expected: "0-1 _local/foo",
+		},
+		{
+			name:     "driver support",
+			db:       &mock.LocalDocer{PutLocalFunc: put},
+			docID:    "_local/foo",
+			expected: "0-1 _local/foo",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			db := &DB{driverDB: test.db}
+			rev, err := db.PutLocal(context.Background(), test.docID, map[string]interface{}{"seq": 3})
+			testy.StatusError(t, test.err, test.status, err)
+			if rev != test.expected {
+				t.Errorf("Unexpected rev: %s", rev)
+			}
+		})
+	}
+}
+
+func TestDeleteLocal(t *testing.T) {
+	del := func(_ context.Context, docID, rev string, _ map[string]interface{}) (string, error) {
+		return fmt.Sprintf("deleted %s %s", docID, rev), nil
+	}
+	tests := []struct {
+		name     string
+		db       driver.DB
+		docID    string
+		expected string
+	}{
+		{
+			name:     "emulated",
+			db:       &mock.DB{DeleteFunc: del},
+			docID:    "foo",
+			expected: "deleted _local/foo 0-1",
+		},
+		{
+			name:     "driver support",
+			db:       &mock.LocalDocer{DeleteLocalFunc: del},
+			docID:    "_local/foo",
+			expected: "deleted _local/foo 0-1",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			db := &DB{driverDB: test.db}
+			rev, err := db.DeleteLocal(context.Background(), test.docID, "0-1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if rev != test.expected {
+				t.Errorf("Unexpected result: %s", rev)
+			}
+		})
+	}
+}
+
+func TestLocalDocs(t *testing.T) {
+	t.Run("not supported", func(t *testing.T) {
+		db := &DB{driverDB: &mock.DB{}}
+		_, err := db.LocalDocs(context.Background())
+		testy.StatusError(t, "kivik: driver does not support local docs listing", StatusNotImplemented, err)
+	})
+	t.Run("error", func(t *testing.T) {
+		db := &DB{driverDB: &mock.LocalDocsLister{
+			LocalDocsFunc: func(_ context.Context, _ map[string]interface{}) (driver.Rows, error) {
+				return nil, errors.Status(StatusForbidden, "list error")
+			},
+		}}
+		_, err := db.LocalDocs(context.Background())
+		testy.StatusError(t, "list error", StatusForbidden, err)
+	})
+	t.Run("success", func(t *testing.T) {
+		ids := []string{"_local/a", "_local/b"}
+		db := &DB{driverDB: &mock.LocalDocsLister{
+			LocalDocsFunc: func(_ context.Context, opts map[string]interface{}) (driver.Rows, error) {
+				if d := diff.Interface(testOptions, opts); d != nil {
+					return nil, fmt.Errorf("Unexpected options:\n%s", d)
+				}
+				var i int
+				return &mock.Rows{
+					NextFunc: func(row *driver.Row) error {
+						if i == len(ids) {
+							return io.EOF
+						}
+						row.ID = ids[i]
+						i++
+						return nil
+					},
+					CloseFunc: func() error { return nil },
+				}, nil
+			},
+		}}
+		rows, err := db.LocalDocs(context.Background(), testOptions)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var result []string
+		for rows.Next() {
+			result = append(result, rows.ID())
+		}
+		if err := rows.Err(); err != nil {
+			t.Fatal(err)
+		}
+		if d := diff.Interface(ids, result); d != nil {
+			t.Error(d)
+		}
+	})
+}
@@ -0,0 +1,129 @@
+package mango
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+// Type ranks, in CouchDB collation order.
+const (
+	rankNull = iota
+	rankFalse
+	rankTrue
+	rankNumber
+	rankString
+	rankArray
+	rankObject
+)
+
+func rank(value interface{}) int {
+	switch t := value.(type) {
+	case nil:
+		return rankNull
+	case bool:
+		if t {
+			return rankTrue
+		}
+		return rankFalse
+	case json.Number, float64:
+		return rankNumber
+	case string:
+		return rankString
+	case []interface{}:
+		return rankArray
+	}
+	return rankObject
+}
+
+// jsonType returns the name of the JSON type of value, as used by the $type
+// operator.
+func jsonType(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case json.Number, float64:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	}
+	return "object"
+}
+
+// compare returns an integer comparing a and b, following the CouchDB view
+// collation rules: null < false < true < numbers < strings < arrays < objects.
+//
+// Strings are compared by their code points, rather than with the ICU
+// collation used by CouchDB, and object members are compared in key order,
+// rather than in the order they appear in the JSON representation. Equality
+// is not affected by either of these differences.
+func compare(a, b interface{}) int {
+	ra, rb := rank(a), rank(b)
+	if ra != rb {
+		return ra - rb
+	}
+	switch ra {
+	case rankNumber:
+		fa, fb := toFloat(a), toFloat(b)
+		switch {
+		case fa < fb:
+			return -1
+		case fa > fb:
+			return 1
+		}
+		return 0
+	case rankString:
+		return strings.Compare(a.(string), b.(string))
+	case rankArray:
+		return compareArrays(a.([]interface{}), b.([]interface{}))
+	case rankObject:
+		return compareObjects(a.(map[string]interface{}), b.(map[string]interface{}))
+	}
+	return 0
+}
+
+func toFloat(value interface{}) float64 {
+	switch t := value.(type) {
+	case json.Number:
+		f, _ := t.Float64()
+		return f
+	case float64:
+		return t
+	}
+	return 0
+}
+
+func compareArrays(a, b []interface{}) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if c := compare(a[i], b[i]); c != 0 {
+			return c
+		}
+	}
+	return len(a) - len(b)
+}
+
+func compareObjects(a, b map[string]interface{}) int {
+	aKeys, bKeys := sortedKeys(a), sortedKeys(b)
+	for i := 0; i < len(aKeys) && i < len(bKeys); i++ {
+		if c := strings.Compare(aKeys[i], bKeys[i]); c != 0 {
+			return c
+		}
+		if c := compare(a[aKeys[i]], b[bKeys[i]]); c != 0 {
+			return c
+		}
+	}
+	return len(aKeys) - len(bKeys)
+}
+
+func sortedKeys(obj map[string]interface{}) []string {
+	keys := make([]string, 0, len(obj))
+	for key := range obj {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,75 @@
+package mango
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/flimzy/diff"
+)
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     interface{}
+		expected int
+	}{
+		{name: "null vs false", a: nil, b: false, expected: -1},
+		{name: "false vs true", a: false, b: true, expected: -1},
+		{name: "true vs number", a: true, b: json.Number("0"), expected: -1},
+		{name: "number vs string", a: json.Number("100"), b: "1", expected: -1},
+		{name: "string vs array", a: "z", b: []interface{}{}, expected: -1},
+		{name: "array vs object", a: []interface{}{"z"}, b: map[string]interface{}{}, expected: -1},
+		{name: "equal numbers", a: json.Number("1"), b: json.Number("1.0"), expected: 0},
+		{name: "numbers", a: json.Number("2"), b: json.Number("10"), expected: -1},
+		{name: "strings", a: "b", b: "a", expected: 1},
+		{name: "arrays by element", a: []interface{}{"a", "b"}, b: []interface{}{"a", "c"}, expected: -1},
+		{name: "arrays by length", a: []interface{}{"a"}, b: []interface{}{"a", "b"}, expected: -1},
+		{
+			name:     "equal objects",
+			a:        map[string]interface{}{"a": json.Number("1"), "b": "x"},
+			b:        map[string]interface{}{"b": "x", "a": json.Number("1")},
+			expected: 0,
+		},
+		{
+			name:     "objects by value",
+			a:        map[string]interface{}{"a": json.Number("2")},
+			b:        map[string]interface{}{"a": json.Number("1")},
+			expected: 1,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := compare(test.a, test.b)
+			switch {
+			case result < 0:
+				result = -1
+			case result > 0:
+				result = 1
+			}
+			if result != test.expected {
+				t.Errorf("Unexpected result: %d", result)
+			}
+		})
+	}
+}
+
+func TestSplitField(t *testing.T) {
+	tests := []struct {
+		field    string
+		expected []string
+	}{
+		{field: "foo", expected: []string{"foo"}},
+		{field: "foo.bar", expected: []string{"foo", "bar"}},
+		{field: `foo\.bar`, expected: []string{"foo.bar"}},
+		{field: `foo\.bar.baz`, expected: []string{"foo.bar", "baz"}},
+		{field: `foo\bar`, expected: []string{`foo\bar`}},
+	}
+	for _, test := range tests {
+		t.Run(test.field, func(t *testing.T) {
+			result := splitField(test.field)
+			if d := diff.Interface(test.expected, result); d != nil {
+				t.Error(d)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package mango
+
+import (
+	"bytes"
+	"strconv"
+)
+
+// splitField splits a dotted field name into its path components. A literal
+// period may be included in a component by escaping it with a backslash.
+func splitField(field string) []string {
+	var path []string
+	var part bytes.Buffer
+	for i := 0; i < len(field); i++ {
+		switch {
+		case field[i] == '\\' && i+1 < len(field) && field[i+1] == '.':
+			part.WriteByte('.')
+			i++
+		case field[i] == '.':
+			path = append(path, part.String())
+			part.Reset()
+		default:
+			part.WriteByte(field[i])
+		}
+	}
+	return append(path, part.String())
+}
+
+// getField returns the value found at path within value. Array elements may
+// be addressed by their numeric index. The second return value is false if
+// the path does not exist.
+func getField(value interface{}, path []string) (interface{}, bool) {
+	for _, name := range path {
+		switch t := value.(type) {
+		case map[string]interface{}:
+			v, ok := t[name]
+			if !ok {
+				return nil, false
+			}
+			value = v
+		case []interface{}:
+			i, err := strconv.Atoi(name)
+			if err != nil || i < 0 || i >= len(t) {
+				return nil, false
+			}
+			value = t[i]
+		default:
+			return nil, false
+		}
+	}
+	return value, true
+}
@@ -0,0 +1,259 @@
+package mango
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+)
+
+// node is a single element of a parsed selector. match is called with the
+// value to which the node applies; for top-level nodes this is the entire
+// document, for conditions on a field it is the field's value.
+type node interface {
+	match(value interface{}) bool
+}
+
+type andNode []node
+
+func (n andNode) match(value interface{}) bool {
+	for _, sub := range n {
+		if !sub.match(value) {
+			return false
+		}
+	}
+	return true
+}
+
+type orNode []node
+
+func (n orNode) match(value interface{}) bool {
+	if len(n) == 0 {
+		return true
+	}
+	for _, sub := range n {
+		if sub.match(value) {
+			return true
+		}
+	}
+	return false
+}
+
+type norNode []node
+
+func (n norNode) match(value interface{}) bool {
+	for _, sub := range n {
+		if sub.match(value) {
+			return false
+		}
+	}
+	return true
+}
+
+type notNode struct {
+	sub node
+}
+
+func (n *notNode) match(value interface{}) bool {
+	return !n.sub.match(value)
+}
+
+// fieldNode applies cond to the value found at path. A missing field matches
+// only {"$exists": false}.
+type fieldNode struct {
+	path []string
+	cond node
+}
+
+func (n *fieldNode) match(value interface{}) bool {
+	sub, ok := getField(value, n.path)
+	if !ok {
+		exists, isExists := n.cond.(existsNode)
+		return isExists && !bool(exists)
+	}
+	return n.cond.match(sub)
+}
+
+type eqNode struct {
+	arg interface{}
+}
+
+func (n *eqNode) match(value interface{}) bool {
+	return compare(value, n.arg) == 0
+}
+
+type cmpNode struct {
+	op  string
+	arg interface{}
+}
+
+func (n *cmpNode) match(value interface{}) bool {
+	c := compare(value, n.arg)
+	switch n.op {
+	case "$lt":
+		return c < 0
+	case "$lte":
+		return c <= 0
+	case "$gt":
+		return c > 0
+	}
+	return c >= 0
+}
+
+// inNode matches if the value equals any of args. If the value is an array,
+// it matches if any element equals any of args.
+type inNode struct {
+	args []interface{}
+}
+
+func (n *inNode) match(value interface{}) bool {
+	values, ok := value.([]interface{})
+	if !ok {
+		values = []interface{}{value}
+	}
+	for _, arg := range n.args {
+		for _, v := range values {
+			if compare(v, arg) == 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// allNode matches an array value which contains all of args.
+type allNode struct {
+	args []interface{}
+}
+
+func (n *allNode) match(value interface{}) bool {
+	values, ok := value.([]interface{})
+	if !ok || len(n.args) == 0 {
+		return false
+	}
+	for _, arg := range n.args {
+		if !contains(values, arg) {
+			return false
+		}
+	}
+	return true
+}
+
+func contains(values []interface{}, arg interface{}) bool {
+	for _, v := range values {
+		if compare(v, arg) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// existsNode is only reached when the field exists; the missing case is
+// handled by fieldNode.
+type existsNode bool
+
+func (n existsNode) match(_ interface{}) bool {
+	return bool(n)
+}
+
+type typeNode string
+
+func (n typeNode) match(value interface{}) bool {
+	return jsonType(value) == string(n)
+}
+
+type sizeNode int64
+
+func (n sizeNode) match(value interface{}) bool {
+	values, ok := value.([]interface{})
+	return ok && int64(len(values)) == int64(n)
+}
+
+type modNode struct {
+	divisor, remainder int64
+}
+
+func (n *modNode) match(value interface{}) bool {
+	i, ok := toInt(value)
+	return ok && i%n.divisor == n.remainder
+}
+
+type regexNode struct {
+	re *regexp.Regexp
+}
+
+func (n *regexNode) match(value interface{}) bool {
+	s, ok := value.(string)
+	return ok && n.re.MatchString(s)
+}
+
+type beginsWithNode string
+
+func (n beginsWithNode) match(value interface{}) bool {
+	s, ok := value.(string)
+	return ok && strings.HasPrefix(s, string(n))
+}
+
+// elemMatchNode matches an array with at least one element matched by sub.
+type elemMatchNode struct {
+	sub node
+}
+
+func (n *elemMatchNode) match(value interface{}) bool {
+	values, ok := value.([]interface{})
+	if !ok {
+		return false
+	}
+	for _, v := range values {
+		if n.sub.match(v) {
+			return true
+		}
+	}
+	return false
+}
+
+// allMatchNode matches a non-empty array, all of whose elements are matched by
+// sub.
+type allMatchNode struct {
+	sub node
+}
+
+func (n *allMatchNode) match(value interface{}) bool {
+	values, ok := value.([]interface{})
+	if !ok || len(values) == 0 {
+		return false
+	}
+	for _, v := range values {
+		if !n.sub.match(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// keyMapMatchNode matches an object with at least one key matched by sub.
+type keyMapMatchNode struct {
+	sub node
+}
+
+func (n *keyMapMatchNode) match(value interface{}) bool {
+	obj, ok := value.(map[string]interface{})
+	if !ok {
+		return false
+	}
+	for key := range obj {
+		if n.sub.match(key) {
+			return true
+		}
+	}
+	return false
+}
+
+// toInt returns the value as an int64, if it is a JSON integer.
+func toInt(value interface{}) (int64, bool) {
+	num, ok := value.(json.Number)
+	if !ok {
+		return 0, false
+	}
+	i, err := num.Int64()
+	return i, err == nil
+}
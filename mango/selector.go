@@ -0,0 +1,295 @@
+// Package mango provides an in-process evaluator for CouchDB Mango selectors,
+// as used by the /_find endpoint. It may be used by drivers which have no
+// native query engine to implement driver.Finder, or by clients which wish to
+// apply a selector to documents they already hold, such as those returned by
+// the changes feed.
+//
+// See http://docs.couchdb.org/en/2.1.1/api/database/find.html#selector-syntax
+package mango // import "github.com/go-kivik/kivik/mango"
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/go-kivik/kivik/errors"
+)
+
+// Selector is a parsed Mango selector, which may be matched against any number
+// of documents.
+type Selector struct {
+	root node
+}
+
+// New parses and validates a Mango selector. If selector is a string, []byte,
+// or json.RawMessage, it is treated as a raw JSON payload. Any other type is
+// marshaled to JSON.
+//
+// The selector must be the value of the "selector" field of a query, not the
+// entire query object.
+func New(selector interface{}) (*Selector, error) {
+	sel, err := toJSON(selector)
+	if err != nil {
+		return nil, err
+	}
+	obj, ok := sel.(map[string]interface{})
+	if !ok {
+		return nil, errors.Status(http.StatusBadRequest, "mango: selector must be a JSON object")
+	}
+	root, err := parseSelector(obj)
+	if err != nil {
+		return nil, err
+	}
+	return &Selector{root: root}, nil
+}
+
+// Match returns true if doc is matched by the selector. doc is converted in the
+// same way as the selector passed to New.
+func (s *Selector) Match(doc interface{}) (bool, error) {
+	value, err := toJSON(doc)
+	if err != nil {
+		return false, err
+	}
+	return s.root.match(value), nil
+}
+
+// Match is a convenience function which parses selector, then matches it
+// against doc. When matching the same selector against many documents, it is
+// more efficient to call New once, and use the Match method of the returned
+// Selector.
+func Match(selector, doc interface{}) (bool, error) {
+	s, err := New(selector)
+	if err != nil {
+		return false, err
+	}
+	return s.Match(doc)
+}
+
+// toJSON converts i to its generic JSON representation. Numbers are decoded
+// as json.Number, so that integers can be distinguished from floats.
+func toJSON(i interface{}) (interface{}, error) {
+	var data []byte
+	switch t := i.(type) {
+	case string:
+		data = []byte(t)
+	case []byte:
+		data = t
+	case json.RawMessage:
+		data = t
+	default:
+		var err error
+		data, err = json.Marshal(i)
+		if err != nil {
+			return nil, errors.WrapStatus(http.StatusBadRequest, err)
+		}
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var value interface{}
+	if err := dec.Decode(&value); err != nil {
+		return nil, errors.WrapStatus(http.StatusBadRequest, err)
+	}
+	return value, nil
+}
+
+func isOperator(key string) bool {
+	return strings.HasPrefix(key, "$")
+}
+
+// parseSelector parses a selector object, which may contain any mix of
+// field names and combination operators. Multiple keys are implicitly
+// combined with $and.
+func parseSelector(sel map[string]interface{}) (node, error) {
+	nodes := make(andNode, 0, len(sel))
+	for _, key := range sortedKeys(sel) {
+		var n node
+		var err error
+		if isOperator(key) {
+			n, err = parseOperator(key, sel[key])
+		} else {
+			n, err = parseField(key, sel[key])
+		}
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, n)
+	}
+	if len(nodes) == 1 {
+		return nodes[0], nil
+	}
+	return nodes, nil
+}
+
+// parseField parses the condition for a single field. A value which is not an
+// object is an implicit $eq. An object may contain operators, which apply to
+// the field itself, and sub-field names, so that {"a": {"b": 1}} is equivalent
+// to {"a.b": 1}.
+func parseField(field string, value interface{}) (node, error) {
+	return parseFieldPath(splitField(field), value)
+}
+
+// parseFieldPath parses the condition for the field at path. As with
+// CouchDB, sub-field names are flattened into the path, so that each
+// condition is applied to the innermost field. Otherwise, a condition such as
+// {"a": {"b": {"$exists": false}}} could not match a document without a.
+func parseFieldPath(path []string, value interface{}) (node, error) {
+	obj, ok := value.(map[string]interface{})
+	if !ok || len(obj) == 0 {
+		return &fieldNode{path: path, cond: &eqNode{arg: value}}, nil
+	}
+	var nodes andNode
+	ops := make(map[string]interface{})
+	for _, key := range sortedKeys(obj) {
+		if isOperator(key) {
+			ops[key] = obj[key]
+			continue
+		}
+		subPath := append(append([]string{}, path...), splitField(key)...)
+		n, err := parseFieldPath(subPath, obj[key])
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, n)
+	}
+	if len(ops) > 0 {
+		cond, err := parseSelector(ops)
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(andNode{&fieldNode{path: path, cond: cond}}, nodes...)
+	}
+	if len(nodes) == 1 {
+		return nodes[0], nil
+	}
+	return nodes, nil
+}
+
+// validTypes are the permitted arguments to the $type operator.
+var validTypes = map[string]bool{
+	"null":    true,
+	"boolean": true,
+	"number":  true,
+	"string":  true,
+	"array":   true,
+	"object":  true,
+}
+
+func parseOperator(op string, arg interface{}) (node, error) {
+	switch op {
+	case "$and", "$or", "$nor":
+		nodes, err := parseSelectorList(op, arg)
+		if err != nil {
+			return nil, err
+		}
+		switch op {
+		case "$and":
+			return andNode(nodes), nil
+		case "$or":
+			return orNode(nodes), nil
+		}
+		return norNode(nodes), nil
+	case "$not", "$elemMatch", "$allMatch", "$keyMapMatch":
+		obj, ok := arg.(map[string]interface{})
+		if !ok {
+			return nil, errors.Statusf(http.StatusBadRequest, "mango: %s requires an object argument", op)
+		}
+		sub, err := parseSelector(obj)
+		if err != nil {
+			return nil, err
+		}
+		switch op {
+		case "$not":
+			return &notNode{sub: sub}, nil
+		case "$elemMatch":
+			return &elemMatchNode{sub: sub}, nil
+		case "$allMatch":
+			return &allMatchNode{sub: sub}, nil
+		}
+		return &keyMapMatchNode{sub: sub}, nil
+	case "$eq":
+		return &eqNode{arg: arg}, nil
+	case "$ne":
+		return &notNode{sub: &eqNode{arg: arg}}, nil
+	case "$lt", "$lte", "$gt", "$gte":
+		return &cmpNode{op: op, arg: arg}, nil
+	case "$in", "$nin", "$all":
+		args, ok := arg.([]interface{})
+		if !ok {
+			return nil, errors.Statusf(http.StatusBadRequest, "mango: %s requires an array argument", op)
+		}
+		switch op {
+		case "$in":
+			return &inNode{args: args}, nil
+		case "$nin":
+			return &notNode{sub: &inNode{args: args}}, nil
+		}
+		return &allNode{args: args}, nil
+	case "$exists":
+		b, ok := arg.(bool)
+		if !ok {
+			return nil, errors.Status(http.StatusBadRequest, "mango: $exists requires a boolean argument")
+		}
+		return existsNode(b), nil
+	case "$type":
+		t, ok := arg.(string)
+		if !ok || !validTypes[t] {
+			return nil, errors.Statusf(http.StatusBadRequest, "mango: invalid argument to $type: %v", arg)
+		}
+		return typeNode(t), nil
+	case "$size":
+		size, ok := toInt(arg)
+		if !ok || size < 0 {
+			return nil, errors.Status(http.StatusBadRequest, "mango: $size requires a non-negative integer argument")
+		}
+		return sizeNode(size), nil
+	case "$mod":
+		args, ok := arg.([]interface{})
+		if ok && len(args) == 2 {
+			divisor, ok1 := toInt(args[0])
+			remainder, ok2 := toInt(args[1])
+			if ok1 && ok2 && divisor != 0 {
+				return &modNode{divisor: divisor, remainder: remainder}, nil
+			}
+		}
+		return nil, errors.Status(http.StatusBadRequest, "mango: $mod requires an argument of [Divisor, Remainder], with a non-zero integer Divisor")
+	case "$regex":
+		pattern, ok := arg.(string)
+		if !ok {
+			return nil, errors.Status(http.StatusBadRequest, "mango: $regex requires a string argument")
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, errors.WrapStatus(http.StatusBadRequest, err)
+		}
+		return &regexNode{re: re}, nil
+	case "$beginsWith":
+		prefix, ok := arg.(string)
+		if !ok {
+			return nil, errors.Status(http.StatusBadRequest, "mango: $beginsWith requires a string argument")
+		}
+		return beginsWithNode(prefix), nil
+	}
+	return nil, errors.Statusf(http.StatusBadRequest, "mango: unknown operator '%s'", op)
+}
+
+func parseSelectorList(op string, arg interface{}) ([]node, error) {
+	list, ok := arg.([]interface{})
+	if !ok {
+		return nil, errors.Statusf(http.StatusBadRequest, "mango: %s requires an array argument", op)
+	}
+	nodes := make([]node, len(list))
+	for i, item := range list {
+		obj, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, errors.Statusf(http.StatusBadRequest, "mango: %s arguments must be objects", op)
+		}
+		n, err := parseSelector(obj)
+		if err != nil {
+			return nil, err
+		}
+		nodes[i] = n
+	}
+	return nodes, nil
+}
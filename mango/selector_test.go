@@ -0,0 +1,284 @@
+package mango
+
+import (
+	"testing"
+
+	"github.com/flimzy/testy"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name     string
+		selector interface{}
+		status   int
+		err      string
+	}{
+		{
+			name:     "invalid JSON",
+			selector: "{",
+			status:   400,
+			err:      "unexpected EOF",
+		},
+		{
+			name:     "not an object",
+			selector: `[]`,
+			status:   400,
+			err:      "mango: selector must be a JSON object",
+		},
+		{
+			name:     "unmarshalable",
+			selector: func() {},
+			status:   400,
+			err:      "json: unsupported type: func()",
+		},
+		{
+			name:     "empty selector",
+			selector: map[string]interface{}{},
+		},
+		{
+			name:     "unknown operator",
+			selector: `{"foo":{"$bar":1}}`,
+			status:   400,
+			err:      "mango: unknown operator '$bar'",
+		},
+		{
+			name:     "invalid $and",
+			selector: `{"$and":{"foo":1}}`,
+			status:   400,
+			err:      "mango: $and requires an array argument",
+		},
+		{
+			name:     "invalid $or member",
+			selector: `{"$or":[1]}`,
+			status:   400,
+			err:      "mango: $or arguments must be objects",
+		},
+		{
+			name:     "invalid $not",
+			selector: `{"$not":[]}`,
+			status:   400,
+			err:      "mango: $not requires an object argument",
+		},
+		{
+			name:     "invalid $in",
+			selector: `{"foo":{"$in":1}}`,
+			status:   400,
+			err:      "mango: $in requires an array argument",
+		},
+		{
+			name:     "invalid $exists",
+			selector: `{"foo":{"$exists":"yes"}}`,
+			status:   400,
+			err:      "mango: $exists requires a boolean argument",
+		},
+		{
+			name:     "invalid $type",
+			selector: `{"foo":{"$type":"integer"}}`,
+			status:   400,
+			err:      "mango: invalid argument to $type: integer",
+		},
+		{
+			name:     "invalid $size",
+			selector: `{"foo":{"$size":1.5}}`,
+			status:   400,
+			err:      "mango: $size requires a non-negative integer argument",
+		},
+		{
+			name:     "invalid $mod",
+			selector: `{"foo":{"$mod":[0,1]}}`,
+			status:   400,
+			err:      "mango: $mod requires an argument of [Divisor, Remainder], with a non-zero integer Divisor",
+		},
+		{
+			name:     "invalid $regex",
+			selector: `{"foo":{"$regex":"("}}`,
+			status:   400,
+			err:      "error parsing regexp: missing closing ): `(`",
+		},
+		{
+			name:     "invalid $beginsWith",
+			selector: `{"foo":{"$beginsWith":1}}`,
+			status:   400,
+			err:      "mango: $beginsWith requires a string argument",
+		},
+		{
+			name:     "nested error",
+			selector: `{"$or":[{"foo":{"$bar":1}}]}`,
+			status:   400,
+			err:      "mango: unknown operator '$bar'",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := New(test.selector)
+			testy.StatusError(t, test.err, test.status, err)
+		})
+	}
+}
+
+func TestMatch(t *testing.T) {
+	doc := `{
+		"_id": "abc",
+		"name": "Bob",
+		"age": 42,
+		"height": 1.8,
+		"admin": true,
+		"nothing": null,
+		"tags": ["a", "b", "c"],
+		"empty": [],
+		"scores": [5, 10, 15],
+		"address": {"city": "Paris", "zip": "75001"},
+		"dotted.key": 1,
+		"pets": [{"name": "Rex", "kind": "dog"}, {"name": "Tom", "kind": "cat"}]
+	}`
+	tests := []struct {
+		name     string
+		selector string
+		expected bool
+	}{
+		{name: "empty", selector: `{}`, expected: true},
+		{name: "implicit eq", selector: `{"name":"Bob"}`, expected: true},
+		{name: "implicit eq mismatch", selector: `{"name":"Alice"}`, expected: false},
+		{name: "missing field", selector: `{"foo":"bar"}`, expected: false},
+		{name: "implicit and", selector: `{"name":"Bob","age":42}`, expected: true},
+		{name: "implicit and mismatch", selector: `{"name":"Bob","age":41}`, expected: false},
+		{name: "dotted field", selector: `{"address.city":"Paris"}`, expected: true},
+		{name: "sub-field object", selector: `{"address":{"city":"Paris"}}`, expected: true},
+		{name: "sub-field object with operator", selector: `{"address":{"city":"Paris","$type":"object"}}`, expected: true},
+		{name: "sub-field of missing parent not exists", selector: `{"foo":{"bar":{"$exists":false}}}`, expected: true},
+		{name: "dotted field of missing parent not exists", selector: `{"foo.bar":{"$exists":false}}`, expected: true},
+		{name: "sub-field of missing parent", selector: `{"foo":{"bar":{"$exists":true}}}`, expected: false},
+		{name: "nested sub-field object", selector: `{"pets":{"0":{"name":"Rex"}}}`, expected: true},
+		{name: "escaped period", selector: `{"dotted\\.key":1}`, expected: true},
+		{name: "array index", selector: `{"tags.1":"b"}`, expected: true},
+		{name: "array index out of range", selector: `{"tags.5":"b"}`, expected: false},
+		{name: "eq array", selector: `{"tags":{"$eq":["a","b","c"]}}`, expected: true},
+		{name: "eq object", selector: `{"address":{"$eq":{"zip":"75001","city":"Paris"}}}`, expected: true},
+		{name: "eq null", selector: `{"nothing":null}`, expected: true},
+		{name: "eq integer as float", selector: `{"age":42.0}`, expected: true},
+		{name: "ne", selector: `{"name":{"$ne":"Alice"}}`, expected: true},
+		{name: "ne missing", selector: `{"foo":{"$ne":"Alice"}}`, expected: false},
+		{name: "gt", selector: `{"age":{"$gt":40}}`, expected: true},
+		{name: "gt equal", selector: `{"age":{"$gt":42}}`, expected: false},
+		{name: "gte", selector: `{"age":{"$gte":42}}`, expected: true},
+		{name: "lt", selector: `{"height":{"$lt":2}}`, expected: true},
+		{name: "lte", selector: `{"height":{"$lte":1.8}}`, expected: true},
+		{name: "range", selector: `{"age":{"$gt":40,"$lt":50}}`, expected: true},
+		{name: "range miss", selector: `{"age":{"$gt":40,"$lt":42}}`, expected: false},
+		{name: "gt collation", selector: `{"name":{"$gt":100}}`, expected: true},
+		{name: "lt collation", selector: `{"name":{"$lt":[]}}`, expected: true},
+		{name: "exists", selector: `{"name":{"$exists":true}}`, expected: true},
+		{name: "exists missing", selector: `{"foo":{"$exists":true}}`, expected: false},
+		{name: "not exists", selector: `{"foo":{"$exists":false}}`, expected: true},
+		{name: "not exists present", selector: `{"name":{"$exists":false}}`, expected: false},
+		{name: "type string", selector: `{"name":{"$type":"string"}}`, expected: true},
+		{name: "type number", selector: `{"age":{"$type":"number"}}`, expected: true},
+		{name: "type null", selector: `{"nothing":{"$type":"null"}}`, expected: true},
+		{name: "type boolean", selector: `{"admin":{"$type":"boolean"}}`, expected: true},
+		{name: "type array", selector: `{"tags":{"$type":"array"}}`, expected: true},
+		{name: "type object", selector: `{"address":{"$type":"object"}}`, expected: true},
+		{name: "type mismatch", selector: `{"tags":{"$type":"object"}}`, expected: false},
+		{name: "in", selector: `{"name":{"$in":["Alice","Bob"]}}`, expected: true},
+		{name: "in miss", selector: `{"name":{"$in":["Alice","Carol"]}}`, expected: false},
+		{name: "in array field", selector: `{"tags":{"$in":["x","c"]}}`, expected: true},
+		{name: "nin", selector: `{"name":{"$nin":["Alice","Carol"]}}`, expected: true},
+		{name: "nin miss", selector: `{"tags":{"$nin":["c"]}}`, expected: false},
+		{name: "nin missing field", selector: `{"foo":{"$nin":["c"]}}`, expected: false},
+		{name: "size", selector: `{"tags":{"$size":3}}`, expected: true},
+		{name: "size empty", selector: `{"empty":{"$size":0}}`, expected: true},
+		{name: "size non-array", selector: `{"name":{"$size":3}}`, expected: false},
+		{name: "mod", selector: `{"age":{"$mod":[5,2]}}`, expected: true},
+		{name: "mod miss", selector: `{"age":{"$mod":[5,3]}}`, expected: false},
+		{name: "mod float", selector: `{"height":{"$mod":[5,3]}}`, expected: false},
+		{name: "regex", selector: `{"name":{"$regex":"^B.b$"}}`, expected: true},
+		{name: "regex non-string", selector: `{"age":{"$regex":"4"}}`, expected: false},
+		{name: "beginsWith", selector: `{"address.zip":{"$beginsWith":"75"}}`, expected: true},
+		{name: "beginsWith miss", selector: `{"address.zip":{"$beginsWith":"76"}}`, expected: false},
+		{name: "all", selector: `{"tags":{"$all":["c","a"]}}`, expected: true},
+		{name: "all miss", selector: `{"tags":{"$all":["c","d"]}}`, expected: false},
+		{name: "all empty", selector: `{"tags":{"$all":[]}}`, expected: false},
+		{name: "elemMatch", selector: `{"scores":{"$elemMatch":{"$gt":12}}}`, expected: true},
+		{name: "elemMatch miss", selector: `{"scores":{"$elemMatch":{"$gt":20}}}`, expected: false},
+		{name: "elemMatch fields", selector: `{"pets":{"$elemMatch":{"kind":"cat","name":"Tom"}}}`, expected: true},
+		{name: "elemMatch fields miss", selector: `{"pets":{"$elemMatch":{"kind":"cat","name":"Rex"}}}`, expected: false},
+		{name: "allMatch", selector: `{"scores":{"$allMatch":{"$gte":5}}}`, expected: true},
+		{name: "allMatch miss", selector: `{"scores":{"$allMatch":{"$gt":5}}}`, expected: false},
+		{name: "allMatch empty", selector: `{"empty":{"$allMatch":{"$gt":5}}}`, expected: false},
+		{name: "keyMapMatch", selector: `{"address":{"$keyMapMatch":{"$eq":"zip"}}}`, expected: true},
+		{name: "keyMapMatch miss", selector: `{"address":{"$keyMapMatch":{"$eq":"street"}}}`, expected: false},
+		{name: "and", selector: `{"$and":[{"name":"Bob"},{"age":{"$gt":40}}]}`, expected: true},
+		{name: "and miss", selector: `{"$and":[{"name":"Bob"},{"age":{"$gt":50}}]}`, expected: false},
+		{name: "or", selector: `{"$or":[{"name":"Alice"},{"age":42}]}`, expected: true},
+		{name: "or miss", selector: `{"$or":[{"name":"Alice"},{"age":41}]}`, expected: false},
+		{name: "or empty", selector: `{"$or":[]}`, expected: true},
+		{name: "nor", selector: `{"$nor":[{"name":"Alice"},{"age":41}]}`, expected: true},
+		{name: "nor miss", selector: `{"$nor":[{"name":"Alice"},{"age":42}]}`, expected: false},
+		{name: "not", selector: `{"$not":{"name":"Alice"}}`, expected: true},
+		{name: "not miss", selector: `{"$not":{"name":"Bob"}}`, expected: false},
+		{name: "field not", selector: `{"age":{"$not":{"$lt":40}}}`, expected: true},
+		{name: "field or", selector: `{"age":{"$or":[{"$lt":10},{"$gt":40}]}}`, expected: true},
+		{name: "_id", selector: `{"_id":{"$gt":null}}`, expected: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := Match(test.selector, doc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result != test.expected {
+				t.Errorf("Unexpected result: %t", result)
+			}
+		})
+	}
+}
+
+func TestSelectorMatch(t *testing.T) {
+	type person struct {
+		Name string `json:"name"`
+		Age  int    `json:"age"`
+	}
+	sel, err := New(map[string]interface{}{
+		"age": map[string]interface{}{"$gte": 18},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name     string
+		doc      interface{}
+		expected bool
+		status   int
+		err      string
+	}{
+		{
+			name:     "struct",
+			doc:      person{Name: "Bob", Age: 42},
+			expected: true,
+		},
+		{
+			name:     "map",
+			doc:      map[string]interface{}{"age": 12},
+			expected: false,
+		},
+		{
+			name:     "raw JSON",
+			doc:      []byte(`{"age":18}`),
+			expected: true,
+		},
+		{
+			name:   "invalid JSON",
+			doc:    []byte(`{"age":`),
+			status: 400,
+			err:    "unexpected EOF",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := sel.Match(test.doc)
+			testy.StatusError(t, test.err, test.status, err)
+			if result != test.expected {
+				t.Errorf("Unexpected result: %t", result)
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/go-kivik/kivik/driver"
+)
+
+// Configer mocks driver.Client and driver.Configer
+type Configer struct {
+	*Client
+	ConfigFunc            func(context.Context, string) (driver.Config, error)
+	ConfigSectionFunc     func(context.Context, string, string) (driver.ConfigSection, error)
+	ConfigValueFunc       func(context.Context, string, string, string) (string, error)
+	SetConfigValueFunc    func(context.Context, string, string, string, string) (string, error)
+	DeleteConfigValueFunc func(context.Context, string, string, string) (string, error)
+}
+
+var _ driver.Configer = &Configer{}
+
+// Config calls c.ConfigFunc
+func (c *Configer) Config(ctx context.Context, node string) (driver.Config, error) {
+	return c.ConfigFunc(ctx, node)
+}
+
+// ConfigSection calls c.ConfigSectionFunc
+func (c *Configer) ConfigSection(ctx context.Context, node, section string) (driver.ConfigSection, error) {
+	return c.ConfigSectionFunc(ctx, node, section)
+}
+
+// ConfigValue calls c.ConfigValueFunc
+func (c *Configer) ConfigValue(ctx context.Context, node, section, key string) (string, error) {
+	return c.ConfigValueFunc(ctx, node, section, key)
+}
+
+// SetConfigValue calls c.SetConfigValueFunc
+func (c *Configer) SetConfigValue(ctx context.Context, node, section, key, value string) (string, error) {
+	return c.SetConfigValueFunc(ctx, node, section, key, value)
+}
+
+// DeleteConfigValue calls c.DeleteConfigValueFunc
+func (c *Configer) DeleteConfigValue(ctx context.Context, node, section, key string) (string, error) {
+	return c.DeleteConfigValueFunc(ctx, node, section, key)
+}
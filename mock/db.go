@@ -198,3 +198,54 @@ var _ driver.AttachmentMetaGetter = &AttachmentMetaGetter{}
 func (db *AttachmentMetaGetter) GetAttachmentMeta(ctx context.Context, docID, rev, filename string, options map[string]interface{}) (*driver.Attachment, error) {
 	return db.GetAttachmentMetaFunc(ctx, docID, rev, filename, options)
 }
+
+// AttachmentRangeGetter mocks a driver.DB and driver.AttachmentRangeGetter
+type AttachmentRangeGetter struct {
+	*DB
+	GetAttachmentRangeFunc func(ctx context.Context, docID, rev, filename string, offset, length int64, options map[string]interface{}) (*driver.Attachment, error)
+}
+
+var _ driver.AttachmentRangeGetter = &AttachmentRangeGetter{}
+
+// GetAttachmentRange calls db.GetAttachmentRangeFunc
+func (db *AttachmentRangeGetter) GetAttachmentRange(ctx context.Context, docID, rev, filename string, offset, length int64, options map[string]interface{}) (*driver.Attachment, error) {
+	return db.GetAttachmentRangeFunc(ctx, docID, rev, filename, offset, length, options)
+}
+
+// DesignFuncCaller mocks a driver.DB and driver.DesignFuncCaller
+type DesignFuncCaller struct {
+	*DB
+	UpdateHandlerFunc func(ctx context.Context, ddoc, funcName, docID string, body interface{}, options map[string]interface{}) (*driver.FunctionResponse, error)
+	ShowFunc          func(ctx context.Context, ddoc, funcName, docID string, options map[string]interface{}) (*driver.FunctionResponse, error)
+	ListFunc          func(ctx context.Context, ddoc, funcName, view string, options map[string]interface{}) (*driver.FunctionResponse, error)
+}
+
+var _ driver.DesignFuncCaller = &DesignFuncCaller{}
+
+// UpdateHandler calls db.UpdateHandlerFunc
+func (db *DesignFuncCaller) UpdateHandler(ctx context.Context, ddoc, funcName, docID string, body interface{}, options map[string]interface{}) (*driver.FunctionResponse, error) {
+	return db.UpdateHandlerFunc(ctx, ddoc, funcName, docID, body, options)
+}
+
+// Show calls db.ShowFunc
+func (db *DesignFuncCaller) Show(ctx context.Context, ddoc, funcName, docID string, options map[string]interface{}) (*driver.FunctionResponse, error) {
+	return db.ShowFunc(ctx, ddoc, funcName, docID, options)
+}
+
+// List calls db.ListFunc
+func (db *DesignFuncCaller) List(ctx context.Context, ddoc, funcName, view string, options map[string]interface{}) (*driver.FunctionResponse, error) {
+	return db.ListFunc(ctx, ddoc, funcName, view, options)
+}
+
+// MultipartPutter mocks a driver.DB and driver.MultipartPutter
+type MultipartPutter struct {
+	*DB
+	PutMultipartFunc func(ctx context.Context, docID string, doc map[string]interface{}, atts []*driver.Attachment, options map[string]interface{}) (string, error)
+}
+
+var _ driver.MultipartPutter = &MultipartPutter{}
+
+// PutMultipart calls db.PutMultipartFunc
+func (db *MultipartPutter) PutMultipart(ctx context.Context, docID string, doc map[string]interface{}, atts []*driver.Attachment, options map[string]interface{}) (string, error) {
+	return db.PutMultipartFunc(ctx, docID, doc, atts, options)
+}
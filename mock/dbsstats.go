@@ -0,0 +1,20 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/go-kivik/kivik/driver"
+)
+
+// DBsStatser mocks driver.Client and driver.DBsStatser
+type DBsStatser struct {
+	*Client
+	DBsStatsFunc func(context.Context, []string) ([]*driver.DBStats, error)
+}
+
+var _ driver.DBsStatser = &DBsStatser{}
+
+// DBsStats calls c.DBsStatsFunc
+func (c *DBsStatser) DBsStats(ctx context.Context, dbNames []string) ([]*driver.DBStats, error) {
+	return c.DBsStatsFunc(ctx, dbNames)
+}
@@ -0,0 +1,20 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/go-kivik/kivik/driver"
+)
+
+// Pinger mocks driver.Client and driver.Pinger
+type Pinger struct {
+	*Client
+	PingFunc func(context.Context) (bool, error)
+}
+
+var _ driver.Pinger = &Pinger{}
+
+// Ping calls c.PingFunc
+func (c *Pinger) Ping(ctx context.Context) (bool, error) {
+	return c.PingFunc(ctx)
+}
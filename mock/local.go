@@ -0,0 +1,45 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/go-kivik/kivik/driver"
+)
+
+// LocalDocer mocks driver.DB and driver.LocalDocer
+type LocalDocer struct {
+	*DB
+	GetLocalFunc    func(context.Context, string, map[string]interface{}) (*driver.Document, error)
+	PutLocalFunc    func(context.Context, string, interface{}, map[string]interface{}) (string, error)
+	DeleteLocalFunc func(context.Context, string, string, map[string]interface{}) (string, error)
+}
+
+var _ driver.LocalDocer = &LocalDocer{}
+
+// GetLocal calls db.GetLocalFunc
+func (db *LocalDocer) GetLocal(ctx context.Context, docID string, opts map[string]interface{}) (*driver.Document, error) {
+	return db.GetLocalFunc(ctx, docID, opts)
+}
+
+// PutLocal calls db.PutLocalFunc
+func (db *LocalDocer) PutLocal(ctx context.Context, docID string, doc interface{}, opts map[string]interface{}) (string, error) {
+	return db.PutLocalFunc(ctx, docID, doc, opts)
+}
+
+// DeleteLocal calls db.DeleteLocalFunc
+func (db *LocalDocer) DeleteLocal(ctx context.Context, docID, rev string, opts map[string]interface{}) (string, error) {
+	return db.DeleteLocalFunc(ctx, docID, rev, opts)
+}
+
+// LocalDocsLister mocks driver.DB and driver.LocalDocsLister
+type LocalDocsLister struct {
+	*DB
+	LocalDocsFunc func(context.Context, map[string]interface{}) (driver.Rows, error)
+}
+
+var _ driver.LocalDocsLister = &LocalDocsLister{}
+
+// LocalDocs calls db.LocalDocsFunc
+func (db *LocalDocsLister) LocalDocs(ctx context.Context, opts map[string]interface{}) (driver.Rows, error) {
+	return db.LocalDocsFunc(ctx, opts)
+}
@@ -0,0 +1,44 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/go-kivik/kivik/driver"
+)
+
+// Partitioner mocks driver.DB and driver.Partitioner
+type Partitioner struct {
+	*DB
+	PartitionAllDocsFunc func(context.Context, string, map[string]interface{}) (driver.Rows, error)
+	PartitionQueryFunc   func(context.Context, string, string, string, map[string]interface{}) (driver.Rows, error)
+	PartitionFindFunc    func(context.Context, string, interface{}) (driver.Rows, error)
+	PartitionExplainFunc func(context.Context, string, interface{}) (*driver.QueryPlan, error)
+	PartitionStatsFunc   func(context.Context, string) (*driver.PartitionStats, error)
+}
+
+var _ driver.Partitioner = &Partitioner{}
+
+// PartitionAllDocs calls db.PartitionAllDocsFunc
+func (db *Partitioner) PartitionAllDocs(ctx context.Context, partition string, opts map[string]interface{}) (driver.Rows, error) {
+	return db.PartitionAllDocsFunc(ctx, partition, opts)
+}
+
+// PartitionQuery calls db.PartitionQueryFunc
+func (db *Partitioner) PartitionQuery(ctx context.Context, partition, ddoc, view string, opts map[string]interface{}) (driver.Rows, error) {
+	return db.PartitionQueryFunc(ctx, partition, ddoc, view, opts)
+}
+
+// PartitionFind calls db.PartitionFindFunc
+func (db *Partitioner) PartitionFind(ctx context.Context, partition string, query interface{}) (driver.Rows, error) {
+	return db.PartitionFindFunc(ctx, partition, query)
+}
+
+// PartitionExplain calls db.PartitionExplainFunc
+func (db *Partitioner) PartitionExplain(ctx context.Context, partition string, query interface{}) (*driver.QueryPlan, error) {
+	return db.PartitionExplainFunc(ctx, partition, query)
+}
+
+// PartitionStats calls db.PartitionStatsFunc
+func (db *Partitioner) PartitionStats(ctx context.Context, partition string) (*driver.PartitionStats, error) {
+	return db.PartitionStatsFunc(ctx, partition)
+}
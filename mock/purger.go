@@ -0,0 +1,32 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/go-kivik/kivik/driver"
+)
+
+// Purger mocks driver.DB and driver.Purger
+type Purger struct {
+	*DB
+	PurgeFunc               func(context.Context, map[string][]string) (*driver.PurgeResult, error)
+	PurgedInfosLimitFunc    func(context.Context) (int, error)
+	SetPurgedInfosLimitFunc func(context.Context, int) error
+}
+
+var _ driver.Purger = &Purger{}
+
+// Purge calls db.PurgeFunc
+func (db *Purger) Purge(ctx context.Context, docRevMap map[string][]string) (*driver.PurgeResult, error) {
+	return db.PurgeFunc(ctx, docRevMap)
+}
+
+// PurgedInfosLimit calls db.PurgedInfosLimitFunc
+func (db *Purger) PurgedInfosLimit(ctx context.Context) (int, error) {
+	return db.PurgedInfosLimitFunc(ctx)
+}
+
+// SetPurgedInfosLimit calls db.SetPurgedInfosLimitFunc
+func (db *Purger) SetPurgedInfosLimit(ctx context.Context, limit int) error {
+	return db.SetPurgedInfosLimitFunc(ctx, limit)
+}
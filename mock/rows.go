@@ -65,3 +65,16 @@ var _ driver.Bookmarker = &Bookmarker{}
 func (r *Bookmarker) Bookmark() string {
 	return r.BookmarkFunc()
 }
+
+// QueryStatser wraps driver.QueryStatser
+type QueryStatser struct {
+	*Rows
+	QueryStatsFunc func() *driver.QueryStats
+}
+
+var _ driver.QueryStatser = &QueryStatser{}
+
+// QueryStats calls r.QueryStatsFunc
+func (r *QueryStatser) QueryStats() *driver.QueryStats {
+	return r.QueryStatsFunc()
+}
@@ -18,3 +18,16 @@ var _ driver.Sessioner = &Sessioner{}
 func (s *Sessioner) Session(ctx context.Context) (*driver.Session, error) {
 	return s.SessionFunc(ctx)
 }
+
+// Logouter mocks driver.Client and driver.Logouter
+type Logouter struct {
+	*Client
+	LogoutFunc func(context.Context) error
+}
+
+var _ driver.Logouter = &Logouter{}
+
+// Logout calls c.LogoutFunc
+func (c *Logouter) Logout(ctx context.Context) error {
+	return c.LogoutFunc(ctx)
+}
@@ -0,0 +1,34 @@
+package mock
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/go-kivik/kivik/driver"
+)
+
+// ActiveTasker mocks driver.Client and driver.ActiveTasker
+type ActiveTasker struct {
+	*Client
+	ActiveTasksFunc func(context.Context) ([]*driver.ActiveTask, error)
+}
+
+var _ driver.ActiveTasker = &ActiveTasker{}
+
+// ActiveTasks calls c.ActiveTasksFunc
+func (c *ActiveTasker) ActiveTasks(ctx context.Context) ([]*driver.ActiveTask, error) {
+	return c.ActiveTasksFunc(ctx)
+}
+
+// NodeStatser mocks driver.Client and driver.NodeStatser
+type NodeStatser struct {
+	*Client
+	NodeStatsFunc func(context.Context, string) (json.RawMessage, error)
+}
+
+var _ driver.NodeStatser = &NodeStatser{}
+
+// NodeStats calls c.NodeStatsFunc
+func (c *NodeStatser) NodeStats(ctx context.Context, node string) (json.RawMessage, error) {
+	return c.NodeStatsFunc(ctx, node)
+}
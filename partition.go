@@ -0,0 +1,225 @@
+package kivik
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/go-kivik/kivik/driver"
+	"github.com/go-kivik/kivik/errors"
+)
+
+// PartitionStats contains partition statistics.
+type PartitionStats struct {
+	// DBName is the name of the database.
+	DBName string
+	// DocCount is the number of documents in the partition.
+	DocCount int64
+	// DeletedDocCount is the number of deleted documents in the partition.
+	DeletedDocCount int64
+	// Partition is the name of the partition.
+	Partition string
+	// ActiveSize is the size, in bytes, of the live data in the partition.
+	ActiveSize int64
+	// ExternalSize is the uncompressed size, in bytes, of the documents in
+	// the partition.
+	ExternalSize int64
+	// RawResponse is the raw response body as returned by the server.
+	RawResponse json.RawMessage
+}
+
+// validatePartitionName returns an error if partition is not a valid
+// partition name.
+func validatePartitionName(partition string) error {
+	if partition == "" {
+		return missingArg("partition")
+	}
+	if strings.HasPrefix(partition, "_") {
+		return errors.Statusf(StatusBadRequest, "kivik: invalid partition name %q: must not begin with an underscore", partition)
+	}
+	if strings.Contains(partition, ":") {
+		return errors.Statusf(StatusBadRequest, "kivik: invalid partition name %q: must not contain a colon", partition)
+	}
+	return nil
+}
+
+// SplitPartitionedID splits a document ID of the form "partition:docid", as
+// required by partitioned databases, and validates both parts. Design and
+// local documents are not partitioned, so for them, partition is empty and no
+// error is returned.
+func SplitPartitionedID(docID string) (partition, id string, err error) {
+	if docID == "" {
+		return "", "", missingArg("docID")
+	}
+	if strings.HasPrefix(docID, "_design/") || strings.HasPrefix(docID, LocalPrefix) {
+		return "", docID, nil
+	}
+	i := strings.Index(docID, ":")
+	if i < 0 {
+		return "", "", errors.Statusf(StatusBadRequest, "kivik: invalid partitioned document ID %q: expected partition:docid", docID)
+	}
+	partition, id = docID[:i], docID[i+1:]
+	if err := validatePartitionName(partition); err != nil {
+		return "", "", err
+	}
+	if id == "" {
+		return "", "", errors.Statusf(StatusBadRequest, "kivik: invalid partitioned document ID %q: expected partition:docid", docID)
+	}
+	return partition, id, nil
+}
+
+// PartitionDB is a handle to a single partition of a partitioned database.
+// Partitioned databases are created by passing the option
+// "partitioned": true to CreateDB.
+type PartitionDB struct {
+	db   *DB
+	name string
+	err  error
+}
+
+// Partition returns a handle to the named partition of the database. If the
+// partition name is invalid, the error is deferred until the first method
+// call on the returned handle.
+func (db *DB) Partition(name string) *PartitionDB {
+	return &PartitionDB{
+		db:   db,
+		name: name,
+		err:  validatePartitionName(name),
+	}
+}
+
+// Name returns the name of the partition.
+func (p *PartitionDB) Name() string {
+	return p.name
+}
+
+func (p *PartitionDB) partitioner() (driver.Partitioner, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	if partitioner, ok := p.db.driverDB.(driver.Partitioner); ok {
+		return partitioner, nil
+	}
+	return nil, errors.Status(StatusNotImplemented, "kivik: driver does not support partitions")
+}
+
+// validateDocID returns an error if docID does not belong to the partition.
+func (p *PartitionDB) validateDocID(docID string) error {
+	if p.err != nil {
+		return p.err
+	}
+	partition, _, err := SplitPartitionedID(docID)
+	if err != nil {
+		return err
+	}
+	if partition != p.name {
+		return errors.Statusf(StatusBadRequest, "kivik: document ID %q does not belong to partition %q", docID, p.name)
+	}
+	return nil
+}
+
+// Get fetches the requested document, which must belong to the partition.
+// Any errors are deferred until the row.ScanDoc call.
+func (p *PartitionDB) Get(ctx context.Context, docID string, options ...Options) *Row {
+	if err := p.validateDocID(docID); err != nil {
+		return &Row{Err: err}
+	}
+	return p.db.Get(ctx, docID, options...)
+}
+
+// Put creates a new doc or updates an existing one, with the specified docID,
+// which must belong to the partition.
+func (p *PartitionDB) Put(ctx context.Context, docID string, doc interface{}, options ...Options) (rev string, err error) {
+	if err := p.validateDocID(docID); err != nil {
+		return "", err
+	}
+	return p.db.Put(ctx, docID, doc, options...)
+}
+
+// AllDocs returns a list of the documents in the partition.
+//
+// See https://docs.couchdb.org/en/3.0.0/api/partitioned-dbs.html#db-partition-partition-all-docs
+func (p *PartitionDB) AllDocs(ctx context.Context, options ...Options) (*Rows, error) {
+	partitioner, err := p.partitioner()
+	if err != nil {
+		return nil, err
+	}
+	opts, err := mergeOptions(options...)
+	if err != nil {
+		return nil, err
+	}
+	rowsi, err := partitioner.PartitionAllDocs(ctx, p.name, opts)
+	if err != nil {
+		return nil, err
+	}
+	return newRows(ctx, rowsi), nil
+}
+
+// Query executes the specified view function, limited to the partition. ddoc
+// and view may or may not be be prefixed with '_design/' and '_view/'
+// respectively.
+//
+// See https://docs.couchdb.org/en/3.0.0/api/partitioned-dbs.html#db-partition-partition-design-ddoc-view-view
+func (p *PartitionDB) Query(ctx context.Context, ddoc, view string, options ...Options) (*Rows, error) {
+	partitioner, err := p.partitioner()
+	if err != nil {
+		return nil, err
+	}
+	opts, err := mergeOptions(options...)
+	if err != nil {
+		return nil, err
+	}
+	ddoc = strings.TrimPrefix(ddoc, "_design/")
+	view = strings.TrimPrefix(view, "_view/")
+	rowsi, err := partitioner.PartitionQuery(ctx, p.name, ddoc, view, opts)
+	if err != nil {
+		return nil, err
+	}
+	return newRows(ctx, rowsi), nil
+}
+
+// Find executes a Mango query, limited to the partition.
+//
+// See https://docs.couchdb.org/en/3.0.0/api/partitioned-dbs.html#db-partition-partition-find
+func (p *PartitionDB) Find(ctx context.Context, query interface{}) (*Rows, error) {
+	partitioner, err := p.partitioner()
+	if err != nil {
+		return nil, err
+	}
+	rowsi, err := partitioner.PartitionFind(ctx, p.name, query)
+	if err != nil {
+		return nil, err
+	}
+	return newRows(ctx, rowsi), nil
+}
+
+// Explain returns the query plan for a Mango query, limited to the partition.
+//
+// See https://docs.couchdb.org/en/3.0.0/api/partitioned-dbs.html#db-partition-partition-explain
+func (p *PartitionDB) Explain(ctx context.Context, query interface{}) (*QueryPlan, error) {
+	partitioner, err := p.partitioner()
+	if err != nil {
+		return nil, err
+	}
+	plan, err := partitioner.PartitionExplain(ctx, p.name, query)
+	if err != nil {
+		return nil, err
+	}
+	return newQueryPlan(plan)
+}
+
+// Stats returns statistics about the partition.
+//
+// See https://docs.couchdb.org/en/3.0.0/api/partitioned-dbs.html#get--db-_partition-partition
+func (p *PartitionDB) Stats(ctx context.Context) (*PartitionStats, error) {
+	partitioner, err := p.partitioner()
+	if err != nil {
+		return nil, err
+	}
+	stats, err := partitioner.PartitionStats(ctx, p.name)
+	if err != nil {
+		return nil, err
+	}
+	s := PartitionStats(*stats)
+	return &s, nil
+}
@@ -0,0 +1,248 @@
+package kivik
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/flimzy/diff"
+	"github.com/flimzy/testy"
+	"github.com/go-kivik/kivik/driver"
+	"github.com/go-kivik/kivik/errors"
+	"github.com/go-kivik/kivik/mock"
+)
+
+func TestSplitPartitionedID(t *testing.T) {
+	tests := []struct {
+		name      string
+		docID     string
+		partition string
+		id        string
+		status    int
+		err       string
+	}{
+		{
+			name:   "empty",
+			status: StatusBadRequest,
+			err:    "kivik: docID required",
+		},
+		{
+			name:   "no partition",
+			docID:  "foo",
+			status: StatusBadRequest,
+			err:    `kivik: invalid partitioned document ID "foo": expected partition:docid`,
+		},
+		{
+			name:   "empty partition",
+			docID:  ":foo",
+			status: StatusBadRequest,
+			err:    "kivik: partition required",
+		},
+		{
+			name:   "underscore partition",
+			docID:  "_foo:bar",
+			status: StatusBadRequest,
+			err:    `kivik: invalid partition name "_foo": must not begin with an underscore`,
+		},
+		{
+			name:   "empty id",
+			docID:  "foo:",
+			status: StatusBadRequest,
+			err:    `kivik: invalid partitioned document ID "foo:": expected partition:docid`,
+		},
+		{
+			name:      "valid",
+			docID:     "sensor-1:reading:42",
+			partition: "sensor-1",
+			id:        "reading:42",
+		},
+		{
+			name:  "design doc",
+			docID: "_design/foo",
+			id:    "_design/foo",
+		},
+		{
+			name:  "local doc",
+			docID: "_local/foo",
+			id:    "_local/foo",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			partition, id, err := SplitPartitionedID(test.docID)
+			testy.StatusError(t, test.err, test.status, err)
+			if partition != test.partition || id != test.id {
+				t.Errorf("Unexpected result: %q, %q", partition, id)
+			}
+		})
+	}
+}
+
+func TestPartitionDocs(t *testing.T) {
+	driverDB := &mock.DB{
+		GetFunc: func(_ context.Context, docID string, _ map[string]interface{}) (*driver.Document, error) {
+			return &driver.Document{Rev: "1-xxx", Body: body(`{}`)}, nil
+		},
+		PutFunc: func(_ context.Context, docID string, _ interface{}, _ map[string]interface{}) (string, error) {
+			return "1-xxx", nil
+		},
+	}
+	db := &DB{driverDB: driverDB}
+	tests := []struct {
+		name      string
+		partition string
+		docID     string
+		status    int
+		err       string
+	}{
+		{
+			name:      "invalid partition",
+			partition: "a:b",
+			docID:     "a:b:c",
+			status:    StatusBadRequest,
+			err:       `kivik: invalid partition name "a:b": must not contain a colon`,
+		},
+		{
+			name:      "wrong partition",
+			partition: "foo",
+			docID:     "bar:baz",
+			status:    StatusBadRequest,
+			err:       `kivik: document ID "bar:baz" does not belong to partition "foo"`,
+		},
+		{
+			name:      "design doc",
+			partition: "foo",
+			docID:     "_design/foo",
+			status:    StatusBadRequest,
+			err:       `kivik: document ID "_design/foo" does not belong to partition "foo"`,
+		},
+		{
+			name:      "valid",
+			partition: "foo",
+			docID:     "foo:baz",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p := db.Partition(test.partition)
+			err := p.Get(context.Background(), test.docID).ScanDoc(&map[string]interface{}{})
+			testy.StatusError(t, test.err, test.status, err)
+			rev, err := p.Put(context.Background(), test.docID, map[string]string{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if rev != "1-xxx" {
+				t.Errorf("Unexpected rev: %s", rev)
+			}
+		})
+	}
+}
+
+func TestPartitionQueries(t *testing.T) {
+	rows := func() driver.Rows {
+		return &mock.Rows{
+			NextFunc:  func(_ *driver.Row) error { return io.EOF },
+			CloseFunc: func() error { return nil },
+		}
+	}
+	checkPartition := func(partition string) error {
+		if partition != "foo" {
+			return fmt.Errorf("Unexpected partition: %s", partition)
+		}
+		return nil
+	}
+	partitioner := &mock.Partitioner{
+		PartitionAllDocsFunc: func(_ context.Context, partition string, opts map[string]interface{}) (driver.Rows, error) {
+			if d := diff.Interface(testOptions, opts); d != nil {
+				return nil, fmt.Errorf("Unexpected options:\n%s", d)
+			}
+			return rows(), checkPartition(partition)
+		},
+		PartitionQueryFunc: func(_ context.Context, partition, ddoc, view string, _ map[string]interface{}) (driver.Rows, error) {
+			if ddoc != "ddoc" || view != "view" {
+				return nil, fmt.Errorf("Unexpected view: %s/%s", ddoc, view)
+			}
+			return rows(), checkPartition(partition)
+		},
+		PartitionFindFunc: func(_ context.Context, partition string, _ interface{}) (driver.Rows, error) {
+			return rows(), checkPartition(partition)
+		},
+		PartitionExplainFunc: func(_ context.Context, partition string, _ interface{}) (*driver.QueryPlan, error) {
+			return &driver.QueryPlan{DBName: "db"}, checkPartition(partition)
+		},
+		PartitionStatsFunc: func(_ context.Context, partition string) (*driver.PartitionStats, error) {
+			return &driver.PartitionStats{DBName: "db", Partition: partition}, checkPartition(partition)
+		},
+	}
+	calls := []struct {
+		name string
+		call func(*PartitionDB) error
+	}{
+		{"AllDocs", func(p *PartitionDB) error {
+			_, err := p.AllDocs(context.Background(), testOptions)
+			return err
+		}},
+		{"Query", func(p *PartitionDB) error {
+			_, err := p.Query(context.Background(), "_design/ddoc", "_view/view")
+			return err
+		}},
+		{"Find", func(p *PartitionDB) error {
+			_, err := p.Find(context.Background(), map[string]interface{}{"selector": map[string]interface{}{}})
+			return err
+		}},
+		{"Explain", func(p *PartitionDB) error {
+			_, err := p.Explain(context.Background(), map[string]interface{}{"selector": map[string]interface{}{}})
+			return err
+		}},
+		{"Stats", func(p *PartitionDB) error {
+			_, err := p.Stats(context.Background())
+			return err
+		}},
+	}
+	for _, c := range calls {
+		t.Run(c.name, func(t *testing.T) {
+			t.Run("not supported", func(t *testing.T) {
+				db := &DB{driverDB: &mock.DB{}}
+				err := c.call(db.Partition("foo"))
+				testy.StatusError(t, "kivik: driver does not support partitions", StatusNotImplemented, err)
+			})
+			t.Run("invalid partition", func(t *testing.T) {
+				db := &DB{driverDB: partitioner}
+				err := c.call(db.Partition(""))
+				testy.StatusError(t, "kivik: partition required", StatusBadRequest, err)
+			})
+			t.Run("success", func(t *testing.T) {
+				db := &DB{driverDB: partitioner}
+				if err := c.call(db.Partition("foo")); err != nil {
+					t.Fatal(err)
+				}
+			})
+		})
+	}
+}
+
+func TestPartitionStats(t *testing.T) {
+	db := &DB{driverDB: &mock.Partitioner{
+		PartitionStatsFunc: func(_ context.Context, partition string) (*driver.PartitionStats, error) {
+			if partition == "missing" {
+				return nil, errors.Status(StatusNotFound, "not found")
+			}
+			return &driver.PartitionStats{DBName: "db", Partition: partition, DocCount: 3, ActiveSize: 100}, nil
+		},
+	}}
+	t.Run("error", func(t *testing.T) {
+		_, err := db.Partition("missing").Stats(context.Background())
+		testy.StatusError(t, "not found", StatusNotFound, err)
+	})
+	t.Run("success", func(t *testing.T) {
+		stats, err := db.Partition("foo").Stats(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := &PartitionStats{DBName: "db", Partition: "foo", DocCount: 3, ActiveSize: 100}
+		if d := diff.Interface(expected, stats); d != nil {
+			t.Error(d)
+		}
+	})
+}
@@ -0,0 +1,129 @@
+package kivik
+
+import (
+	"context"
+
+	"github.com/go-kivik/kivik/driver"
+	"github.com/go-kivik/kivik/errors"
+)
+
+// PurgeResult is the result of a purge request.
+type PurgeResult struct {
+	// Seq is the purge sequence number, as reported by CouchDB 1.x. It is
+	// zero for servers which do not report it.
+	Seq int64 `json:"purge_seq"`
+	// Purged is a map of document IDs to the revisions which were purged.
+	Purged map[string][]string `json:"purged"`
+}
+
+func (db *DB) purger() (driver.Purger, error) {
+	if purger, ok := db.driverDB.(driver.Purger); ok {
+		return purger, nil
+	}
+	return nil, errors.Status(StatusNotImplemented, "kivik: driver does not support purge")
+}
+
+// Purge permanently removes the references to the requested document
+// revisions, as a map of document IDs to revisions. Unlike Delete, no trace of
+// the purged revisions remains in the database, and the purge is not
+// replicated.
+//
+// See http://docs.couchdb.org/en/2.3.0/api/database/misc.html#db-purge
+func (db *DB) Purge(ctx context.Context, docRevMap map[string][]string) (*PurgeResult, error) {
+	if len(docRevMap) == 0 {
+		return nil, missingArg("docRevMap")
+	}
+	for docID, revs := range docRevMap {
+		if docID == "" {
+			return nil, missingArg("docID")
+		}
+		if len(revs) == 0 {
+			return nil, errors.Statusf(StatusBadRequest, "kivik: revisions required for %s", docID)
+		}
+	}
+	purger, err := db.purger()
+	if err != nil {
+		return nil, err
+	}
+	res, err := purger.Purge(ctx, docRevMap)
+	if err != nil {
+		return nil, err
+	}
+	result := PurgeResult(*res)
+	return &result, nil
+}
+
+// PurgeDoc purges all leaf revisions of the document, as fetched with
+// open_revs=all, which permanently removes the document from the database.
+//
+// PurgeDoc is not atomic; a revision created after the leaves are fetched is
+// not purged.
+func (db *DB) PurgeDoc(ctx context.Context, docID string) (*PurgeResult, error) {
+	if docID == "" {
+		return nil, missingArg("docID")
+	}
+	purger, err := db.purger()
+	if err != nil {
+		return nil, err
+	}
+	revs, err := db.leafRevs(ctx, docID)
+	if err != nil {
+		return nil, err
+	}
+	if len(revs) == 0 {
+		return nil, errors.Statusf(StatusNotFound, "kivik: document %s not found", docID)
+	}
+	res, err := purger.Purge(ctx, map[string][]string{docID: revs})
+	if err != nil {
+		return nil, err
+	}
+	result := PurgeResult(*res)
+	return &result, nil
+}
+
+// leafRevs returns the leaf revisions of the document, including deleted
+// leaves.
+func (db *DB) leafRevs(ctx context.Context, docID string) ([]string, error) {
+	var leaves []struct {
+		OK *struct {
+			Rev string `json:"_rev"`
+		} `json:"ok"`
+	}
+	if err := db.Get(ctx, docID, Options{"open_revs": "all"}).ScanDoc(&leaves); err != nil {
+		return nil, err
+	}
+	revs := make([]string, 0, len(leaves))
+	for _, leaf := range leaves {
+		if leaf.OK != nil && leaf.OK.Rev != "" {
+			revs = append(revs, leaf.OK.Rev)
+		}
+	}
+	return revs, nil
+}
+
+// PurgedInfosLimit returns the maximum number of historical purges retained
+// by the database.
+//
+// See http://docs.couchdb.org/en/2.3.0/api/database/misc.html#get--db-_purged_infos_limit
+func (db *DB) PurgedInfosLimit(ctx context.Context) (int, error) {
+	purger, err := db.purger()
+	if err != nil {
+		return 0, err
+	}
+	return purger.PurgedInfosLimit(ctx)
+}
+
+// SetPurgedInfosLimit sets the maximum number of historical purges retained
+// by the database.
+//
+// See http://docs.couchdb.org/en/2.3.0/api/database/misc.html#put--db-_purged_infos_limit
+func (db *DB) SetPurgedInfosLimit(ctx context.Context, limit int) error {
+	if limit < 1 {
+		return errors.Status(StatusBadRequest, "kivik: limit must be positive")
+	}
+	purger, err := db.purger()
+	if err != nil {
+		return err
+	}
+	return purger.SetPurgedInfosLimit(ctx, limit)
+}
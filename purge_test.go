@@ -0,0 +1,192 @@
+package kivik
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/flimzy/diff"
+	"github.com/flimzy/testy"
+	"github.com/go-kivik/kivik/driver"
+	"github.com/go-kivik/kivik/errors"
+	"github.com/go-kivik/kivik/mock"
+)
+
+// echoPurger returns a Purger which reports all requested revisions purged.
+func echoPurger(db *mock.DB) *mock.Purger {
+	return &mock.Purger{
+		DB: db,
+		PurgeFunc: func(_ context.Context, docRevMap map[string][]string) (*driver.PurgeResult, error) {
+			return &driver.PurgeResult{Purged: docRevMap}, nil
+		},
+	}
+}
+
+func TestPurge(t *testing.T) {
+	tests := []struct {
+		name      string
+		db        driver.DB
+		docRevMap map[string][]string
+		expected  *PurgeResult
+		status    int
+		err       string
+	}{
+		{
+			name:   "no docs",
+			db:     echoPurger(nil),
+			status: StatusBadRequest,
+			err:    "kivik: docRevMap required",
+		},
+		{
+			name:      "no revs",
+			db:        echoPurger(nil),
+			docRevMap: map[string][]string{"foo": {}},
+			status:    StatusBadRequest,
+			err:       "kivik: revisions required for foo",
+		},
+		{
+			name:      "not supported",
+			db:        &mock.DB{},
+			docRevMap: map[string][]string{"foo": {"1-xxx"}},
+			status:    StatusNotImplemented,
+			err:       "kivik: driver does not support purge",
+		},
+		{
+			name: "error",
+			db: &mock.Purger{
+				PurgeFunc: func(_ context.Context, _ map[string][]string) (*driver.PurgeResult, error) {
+					return nil, errors.Status(StatusForbidden, "purge error")
+				},
+			},
+			docRevMap: map[string][]string{"foo": {"1-xxx"}},
+			status:    StatusForbidden,
+			err:       "purge error",
+		},
+		{
+			name:      "success",
+			db:        echoPurger(nil),
+			docRevMap: map[string][]string{"foo": {"1-xxx", "2-yyy"}},
+			expected:  &PurgeResult{Purged: map[string][]string{"foo": {"1-xxx", "2-yyy"}}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			db := &DB{driverDB: test.db}
+			result, err := db.Purge(context.Background(), test.docRevMap)
+			testy.StatusError(t, test.err, test.status, err)
+			if d := diff.Interface(test.expected, result); d != nil {
+				t.Error(d)
+			}
+		})
+	}
+}
+
+func TestPurgeDoc(t *testing.T) {
+	openRevs := func(response string) *mock.DB {
+		return &mock.DB{
+			GetFunc: func(_ context.Context, docID string, opts map[string]interface{}) (*driver.Document, error) {
+				if d := diff.Interface(map[string]interface{}{"open_revs": "all"}, opts); d != nil {
+					return nil, fmt.Errorf("Unexpected options:\n%s", d)
+				}
+				if docID != "foo" {
+					return nil, errors.Status(StatusNotFound, "missing")
+				}
+				return &driver.Document{Body: body(response)}, nil
+			},
+		}
+	}
+	tests := []struct {
+		name     string
+		db       driver.DB
+		docID    string
+		expected *PurgeResult
+		status   int
+		err      string
+	}{
+		{
+			name:   "missing doc id",
+			db:     echoPurger(nil),
+			status: StatusBadRequest,
+			err:    "kivik: docID required",
+		},
+		{
+			name:   "not supported",
+			db:     openRevs(`[]`),
+			docID:  "foo",
+			status: StatusNotImplemented,
+			err:    "kivik: driver does not support purge",
+		},
+		{
+			name:   "get error",
+			db:     echoPurger(openRevs(`[]`)),
+			docID:  "bar",
+			status: StatusNotFound,
+			err:    "missing",
+		},
+		{
+			name:   "no leaves",
+			db:     echoPurger(openRevs(`[{"missing":"1-xxx"}]`)),
+			docID:  "foo",
+			status: StatusNotFound,
+			err:    "kivik: document foo not found",
+		},
+		{
+			name:     "conflicts and deleted leaf",
+			db:       echoPurger(openRevs(`[{"ok":{"_id":"foo","_rev":"2-aaa"}},{"ok":{"_id":"foo","_rev":"3-bbb","_deleted":true}},{"missing":"1-ccc"}]`)),
+			docID:    "foo",
+			expected: &PurgeResult{Purged: map[string][]string{"foo": {"2-aaa", "3-bbb"}}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			db := &DB{driverDB: test.db}
+			result, err := db.PurgeDoc(context.Background(), test.docID)
+			testy.StatusError(t, test.err, test.status, err)
+			if d := diff.Interface(test.expected, result); d != nil {
+				t.Error(d)
+			}
+		})
+	}
+}
+
+func TestPurgedInfosLimit(t *testing.T) {
+	t.Run("not supported", func(t *testing.T) {
+		db := &DB{driverDB: &mock.DB{}}
+		_, err := db.PurgedInfosLimit(context.Background())
+		testy.StatusError(t, "kivik: driver does not support purge", StatusNotImplemented, err)
+	})
+	t.Run("get", func(t *testing.T) {
+		db := &DB{driverDB: &mock.Purger{
+			PurgedInfosLimitFunc: func(_ context.Context) (int, error) {
+				return 1000, nil
+			},
+		}}
+		limit, err := db.PurgedInfosLimit(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if limit != 1000 {
+			t.Errorf("Unexpected limit: %d", limit)
+		}
+	})
+	t.Run("set invalid", func(t *testing.T) {
+		db := &DB{driverDB: &mock.Purger{}}
+		err := db.SetPurgedInfosLimit(context.Background(), 0)
+		testy.StatusError(t, "kivik: limit must be positive", StatusBadRequest, err)
+	})
+	t.Run("set", func(t *testing.T) {
+		var limit int
+		db := &DB{driverDB: &mock.Purger{
+			SetPurgedInfosLimitFunc: func(_ context.Context, l int) error {
+				limit = l
+				return nil
+			},
+		}}
+		if err := db.SetPurgedInfosLimit(context.Background(), 500); err != nil {
+			t.Fatal(err)
+		}
+		if limit != 500 {
+			t.Errorf("Unexpected limit: %d", limit)
+		}
+	})
+}
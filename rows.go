@@ -3,6 +3,7 @@ package kivik
 import (
 	"context"
 	"strings"
+	"time"
 
 	"github.com/go-kivik/kivik/driver"
 	"github.com/go-kivik/kivik/errors"
@@ -165,3 +166,43 @@ func (r *Rows) Bookmark() string {
 	}
 	return ""
 }
+
+// ExecutionStats contains the execution statistics of a Mango query.
+type ExecutionStats struct {
+	// TotalKeysExamined is the number of index keys examined.
+	TotalKeysExamined int64
+	// TotalDocsExamined is the number of documents fetched from the database
+	// or index.
+	TotalDocsExamined int64
+	// TotalQuorumDocsExamined is the number of documents fetched from the
+	// database using an out-of-band document fetch. This is only non-zero when
+	// read quorum > 1 is specified in the query parameters.
+	TotalQuorumDocsExamined int64
+	// ResultsReturned is the number of results returned from the query.
+	ResultsReturned int64
+	// ExecutionTime is the total execution time, as measured by the database.
+	ExecutionTime time.Duration
+}
+
+// ExecutionStats returns the execution statistics of a Mango query, if they
+// were requested by setting the execution_stats field of the query to true,
+// and the driver supports reporting them. Otherwise it returns nil. As with
+// Warning, this value is only guaranteed to be set after all result rows have
+// been enumerated through by Next.
+func (r *Rows) ExecutionStats() *ExecutionStats {
+	s, ok := r.rowsi.(driver.QueryStatser)
+	if !ok {
+		return nil
+	}
+	stats := s.QueryStats()
+	if stats == nil {
+		return nil
+	}
+	return &ExecutionStats{
+		TotalKeysExamined:       stats.TotalKeysExamined,
+		TotalDocsExamined:       stats.TotalDocsExamined,
+		TotalQuorumDocsExamined: stats.TotalQuorumDocsExamined,
+		ResultsReturned:         stats.ResultsReturned,
+		ExecutionTime:           time.Duration(stats.ExecutionTime * float64(time.Millisecond)),
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/flimzy/diff"
 	"github.com/flimzy/testy"
@@ -332,3 +333,41 @@ func TestBookmark(t *testing.T) {
 		}
 	})
 }
+
+func TestExecutionStats(t *testing.T) {
+	t.Run("QueryStatser", func(t *testing.T) {
+		r := newRows(context.Background(), &mock.QueryStatser{
+			QueryStatsFunc: func() *driver.QueryStats {
+				return &driver.QueryStats{
+					TotalKeysExamined: 10,
+					TotalDocsExamined: 5,
+					ResultsReturned:   2,
+					ExecutionTime:     1.5,
+				}
+			},
+		})
+		expected := &ExecutionStats{
+			TotalKeysExamined: 10,
+			TotalDocsExamined: 5,
+			ResultsReturned:   2,
+			ExecutionTime:     1500 * time.Microsecond,
+		}
+		if d := diff.Interface(expected, r.ExecutionStats()); d != nil {
+			t.Error(d)
+		}
+	})
+	t.Run("No stats", func(t *testing.T) {
+		r := newRows(context.Background(), &mock.QueryStatser{
+			QueryStatsFunc: func() *driver.QueryStats { return nil },
+		})
+		if s := r.ExecutionStats(); s != nil {
+			t.Errorf("Unexpected stats: %v", s)
+		}
+	})
+	t.Run("Non QueryStatser", func(t *testing.T) {
+		r := newRows(context.Background(), &mock.Rows{})
+		if s := r.ExecutionStats(); s != nil {
+			t.Errorf("Unexpected stats: %v", s)
+		}
+	})
+}
@@ -1,5 +1,14 @@
 package kivik
 
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"sort"
+
+	"github.com/go-kivik/kivik/errors"
+)
+
 // Members represents the members of a database security document.
 type Members struct {
 	Names []string `json:"names,omitempty"`
@@ -10,4 +19,301 @@ type Members struct {
 type Security struct {
 	Admins  Members `json:"admins"`
 	Members Members `json:"members"`
+	// Extra holds any fields other than admins and members, which are
+	// preserved when the document is read and written.
+	Extra map[string]interface{} `json:"-"`
+}
+
+type security Security
+
+// MarshalJSON satisfies the json.Marshaler interface.
+func (s Security) MarshalJSON() ([]byte, error) {
+	return marshalWithExtra(security(s), s.Extra)
+}
+
+// UnmarshalJSON satisfies the json.Unmarshaler interface.
+func (s *Security) UnmarshalJSON(data []byte) error {
+	var sec security
+	if err := json.Unmarshal(data, &sec); err != nil {
+		return err
+	}
+	extra, err := unmodeledFields(data, sec)
+	if err != nil {
+		return err
+	}
+	*s = Security(sec)
+	s.Extra = extra
+	return nil
+}
+
+// AccessLevel is a level of access to a database, granted by its security
+// document.
+type AccessLevel int
+
+// Access levels, for use with Security.Allows.
+const (
+	// MemberAccess permits reading and writing documents other than design
+	// documents.
+	MemberAccess AccessLevel = iota + 1
+	// AdminAccess additionally permits writing design documents and the
+	// security document.
+	AdminAccess
+)
+
+// serverAdminRole is the role held by server administrators, who have admin
+// access to every database.
+const serverAdminRole = "_admin"
+
+// Allows returns true if the security document grants the user described by
+// session the requested level of access. A nil session, or one with no Name,
+// describes an anonymous user. As with CouchDB, server administrators have
+// admin access to every database, admins are also members, and if no member
+// names or roles are defined, the database is public, so every user is a
+// member.
+func (s *Security) Allows(session *Session, level AccessLevel) bool {
+	if session == nil {
+		session = &Session{}
+	}
+	if contains(session.Roles, serverAdminRole) || s.Admins.includes(session) {
+		return true
+	}
+	if level != MemberAccess {
+		return false
+	}
+	if len(s.Members.Names) == 0 && len(s.Members.Roles) == 0 {
+		return true
+	}
+	return s.Members.includes(session)
+}
+
+// includes returns true if the session's user is listed by name, or holds any
+// of the listed roles.
+func (m *Members) includes(session *Session) bool {
+	if session.Name != "" && contains(m.Names, session.Name) {
+		return true
+	}
+	for _, role := range session.Roles {
+		if contains(m.Roles, role) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m Members) clone() Members {
+	return Members{
+		Names: append([]string(nil), m.Names...),
+		Roles: append([]string(nil), m.Roles...),
+	}
+}
+
+func (m Members) equal(o Members) bool {
+	return sameStrings(m.Names, o.Names) && sameStrings(m.Roles, o.Roles)
+}
+
+func (s *Security) clone() *Security {
+	var extra map[string]interface{}
+	if s.Extra != nil {
+		extra = make(map[string]interface{}, len(s.Extra))
+		for name, value := range s.Extra {
+			extra[name] = value
+		}
+	}
+	return &Security{
+		Admins:  s.Admins.clone(),
+		Members: s.Members.clone(),
+		Extra:   extra,
+	}
+}
+
+func (s *Security) equal(o *Security) bool {
+	if len(s.Extra) != 0 || len(o.Extra) != 0 {
+		if !reflect.DeepEqual(s.Extra, o.Extra) {
+			return false
+		}
+	}
+	return s.Admins.equal(o.Admins) && s.Members.equal(o.Members)
+}
+
+func contains(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// sameStrings returns true if a and b contain the same strings, regardless of
+// order.
+func sameStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	a = append([]string(nil), a...)
+	b = append([]string(nil), b...)
+	sort.Strings(a)
+	sort.Strings(b)
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func addString(list []string, value string) []string {
+	if contains(list, value) {
+		return list
+	}
+	return append(list, value)
+}
+
+func removeString(list []string, value string) []string {
+	result := list[:0]
+	for _, v := range list {
+		if v != value {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// maxSecurityAttempts is the number of times UpdateSecurity writes the
+// security document before giving up.
+const maxSecurityAttempts = 5
+
+// UpdateSecurity modifies the database's security document by calling fn
+// with the current document, then storing the result, if changed. Fields
+// other than admins and members are preserved.
+//
+// The security document has no revision, so the server cannot detect
+// conflicting updates, and UpdateSecurity cannot prevent lost updates. A
+// change made by another client between UpdateSecurity reading the document
+// and writing it is overwritten. Only the reverse case is detected: the
+// document is read back after writing, and if a concurrent update has
+// overwritten this change, fn is applied again to the newly read document.
+// For this reason, fn may be called more than once, and should be idempotent.
+// If the change cannot be made to stick, an error with status StatusConflict
+// is returned. Where several clients manage the same security document, they
+// must coordinate their updates by other means.
+//
+// The resulting security document is returned.
+func (db *DB) UpdateSecurity(ctx context.Context, fn func(*Security) error) (*Security, error) {
+	current, err := db.Security(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for attempt := 0; ; attempt++ {
+		updated := current.clone()
+		if err := fn(updated); err != nil {
+			return nil, err
+		}
+		if updated.equal(current) {
+			return current, nil
+		}
+		if attempt == maxSecurityAttempts {
+			return nil, errors.Status(StatusConflict, "kivik: security document modified concurrently")
+		}
+		if err := db.SetSecurity(ctx, updated); err != nil {
+			return nil, err
+		}
+		if current, err = db.Security(ctx); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (db *DB) updateSecurity(ctx context.Context, fn func(*Security)) error {
+	_, err := db.UpdateSecurity(ctx, func(sec *Security) error {
+		fn(sec)
+		return nil
+	})
+	return err
+}
+
+// AddAdmin grants the named user admin access to the database.
+func (db *DB) AddAdmin(ctx context.Context, name string) error {
+	if name == "" {
+		return missingArg("name")
+	}
+	return db.updateSecurity(ctx, func(sec *Security) {
+		sec.Admins.Names = addString(sec.Admins.Names, name)
+	})
+}
+
+// RemoveAdmin revokes the named user's admin access to the database.
+func (db *DB) RemoveAdmin(ctx context.Context, name string) error {
+	if name == "" {
+		return missingArg("name")
+	}
+	return db.updateSecurity(ctx, func(sec *Security) {
+		sec.Admins.Names = removeString(sec.Admins.Names, name)
+	})
+}
+
+// AddAdminRole grants users with the role admin access to the database.
+func (db *DB) AddAdminRole(ctx context.Context, role string) error {
+	if role == "" {
+		return missingArg("role")
+	}
+	return db.updateSecurity(ctx, func(sec *Security) {
+		sec.Admins.Roles = addString(sec.Admins.Roles, role)
+	})
+}
+
+// RemoveAdminRole revokes admin access to the database from users with the
+// role.
+func (db *DB) RemoveAdminRole(ctx context.Context, role string) error {
+	if role == "" {
+		return missingArg("role")
+	}
+	return db.updateSecurity(ctx, func(sec *Security) {
+		sec.Admins.Roles = removeString(sec.Admins.Roles, role)
+	})
+}
+
+// AddMember grants the named user member access to the database. Note that
+// adding the first member makes a public database private.
+func (db *DB) AddMember(ctx context.Context, name string) error {
+	if name == "" {
+		return missingArg("name")
+	}
+	return db.updateSecurity(ctx, func(sec *Security) {
+		sec.Members.Names = addString(sec.Members.Names, name)
+	})
+}
+
+// RemoveMember revokes the named user's member access to the database. Note
+// that removing the last member makes a private database public.
+func (db *DB) RemoveMember(ctx context.Context, name string) error {
+	if name == "" {
+		return missingArg("name")
+	}
+	return db.updateSecurity(ctx, func(sec *Security) {
+		sec.Members.Names = removeString(sec.Members.Names, name)
+	})
+}
+
+// AddMemberRole grants users with the role member access to the database.
+// Note that adding the first member role makes a public database private.
+func (db *DB) AddMemberRole(ctx context.Context, role string) error {
+	if role == "" {
+		return missingArg("role")
+	}
+	return db.updateSecurity(ctx, func(sec *Security) {
+		sec.Members.Roles = addString(sec.Members.Roles, role)
+	})
+}
+
+// RemoveMemberRole revokes member access to the database from users with the
+// role. Note that removing the last member role makes a private database
+// public.
+func (db *DB) RemoveMemberRole(ctx context.Context, role string) error {
+	if role == "" {
+		return missingArg("role")
+	}
+	return db.updateSecurity(ctx, func(sec *Security) {
+		sec.Members.Roles = removeString(sec.Members.Roles, role)
+	})
 }
@@ -0,0 +1,364 @@
+package kivik
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/flimzy/diff"
+	"github.com/flimzy/testy"
+	"github.com/go-kivik/kivik/driver"
+	"github.com/go-kivik/kivik/errors"
+	"github.com/go-kivik/kivik/mock"
+)
+
+func TestSecurityAllows(t *testing.T) {
+	private := &Security{
+		Admins:  Members{Names: []string{"bob"}, Roles: []string{"boss"}},
+		Members: Members{Names: []string{"alice"}, Roles: []string{"staff"}},
+	}
+	public := &Security{
+		Admins: Members{Names: []string{"bob"}},
+	}
+	tests := []struct {
+		name     string
+		security *Security
+		session  *Session
+		level    AccessLevel
+		expected bool
+	}{
+		{
+			name:     "anonymous, public",
+			security: public,
+			level:    MemberAccess,
+			expected: true,
+		},
+		{
+			name:     "anonymous, private",
+			security: private,
+			level:    MemberAccess,
+			expected: false,
+		},
+		{
+			name:     "anonymous admin, public",
+			security: public,
+			level:    AdminAccess,
+			expected: false,
+		},
+		{
+			name:     "member by name",
+			security: private,
+			session:  &Session{Name: "alice"},
+			level:    MemberAccess,
+			expected: true,
+		},
+		{
+			name:     "member by role",
+			security: private,
+			session:  &Session{Name: "carol", Roles: []string{"staff"}},
+			level:    MemberAccess,
+			expected: true,
+		},
+		{
+			name:     "member is not admin",
+			security: private,
+			session:  &Session{Name: "alice", Roles: []string{"staff"}},
+			level:    AdminAccess,
+			expected: false,
+		},
+		{
+			name:     "non-member",
+			security: private,
+			session:  &Session{Name: "dave", Roles: []string{"guest"}},
+			level:    MemberAccess,
+			expected: false,
+		},
+		{
+			name:     "admin by name is member",
+			security: private,
+			session:  &Session{Name: "bob"},
+			level:    MemberAccess,
+			expected: true,
+		},
+		{
+			name:     "admin by role",
+			security: private,
+			session:  &Session{Name: "erin", Roles: []string{"boss"}},
+			level:    AdminAccess,
+			expected: true,
+		},
+		{
+			name:     "server admin",
+			security: private,
+			session:  &Session{Name: "root", Roles: []string{"_admin"}},
+			level:    AdminAccess,
+			expected: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if result := test.security.Allows(test.session, test.level); result != test.expected {
+				t.Errorf("Unexpected result: %t", result)
+			}
+		})
+	}
+}
+
+// securityDB returns a mock DB storing a security document. If interfere is
+// not nil, it is called after each write, to simulate a concurrent update.
+func securityDB(sec *driver.Security, writes *int, interfere func(*driver.Security)) *mock.DB {
+	return &mock.DB{
+		SecurityFunc: func(_ context.Context) (*driver.Security, error) {
+			s := *sec
+			return &s, nil
+		},
+		SetSecurityFunc: func(_ context.Context, s *driver.Security) error {
+			*writes++
+			*sec = *s
+			if interfere != nil {
+				interfere(sec)
+			}
+			return nil
+		},
+	}
+}
+
+func TestSecurityHelpers(t *testing.T) {
+	initial := func() *driver.Security {
+		return &driver.Security{
+			Admins:  driver.Members{Names: []string{"bob"}, Roles: []string{"boss"}},
+			Members: driver.Members{Names: []string{"alice"}, Roles: []string{"staff"}},
+		}
+	}
+	tests := []struct {
+		name     string
+		call     func(*DB) error
+		expected *driver.Security
+		writes   int
+		status   int
+		err      string
+	}{
+		{
+			name:   "AddAdmin, missing name",
+			call:   func(db *DB) error { return db.AddAdmin(context.Background(), "") },
+			status: StatusBadRequest,
+			err:    "kivik: name required",
+		},
+		{
+			name: "AddAdmin",
+			call: func(db *DB) error { return db.AddAdmin(context.Background(), "carol") },
+			expected: &driver.Security{
+				Admins:  driver.Members{Names: []string{"bob", "carol"}, Roles: []string{"boss"}},
+				Members: driver.Members{Names: []string{"alice"}, Roles: []string{"staff"}},
+			},
+			writes: 1,
+		},
+		{
+			name:     "AddAdmin, already present",
+			call:     func(db *DB) error { return db.AddAdmin(context.Background(), "bob") },
+			expected: initial(),
+		},
+		{
+			name: "RemoveAdmin",
+			call: func(db *DB) error { return db.RemoveAdmin(context.Background(), "bob") },
+			expected: &driver.Security{
+				Admins:  driver.Members{Names: []string{}, Roles: []string{"boss"}},
+				Members: driver.Members{Names: []string{"alice"}, Roles: []string{"staff"}},
+			},
+			writes: 1,
+		},
+		{
+			name:   "AddAdminRole, missing role",
+			call:   func(db *DB) error { return db.AddAdminRole(context.Background(), "") },
+			status: StatusBadRequest,
+			err:    "kivik: role required",
+		},
+		{
+			name: "AddAdminRole",
+			call: func(db *DB) error { return db.AddAdminRole(context.Background(), "ops") },
+			expected: &driver.Security{
+				Admins:  driver.Members{Names: []string{"bob"}, Roles: []string{"boss", "ops"}},
+				Members: driver.Members{Names: []string{"alice"}, Roles: []string{"staff"}},
+			},
+			writes: 1,
+		},
+		{
+			name: "RemoveAdminRole",
+			call: func(db *DB) error { return db.RemoveAdminRole(context.Background(), "boss") },
+			expected: &driver.Security{
+				Admins:  driver.Members{Names: []string{"bob"}, Roles: []string{}},
+				Members: driver.Members{Names: []string{"alice"}, Roles: []string{"staff"}},
+			},
+			writes: 1,
+		},
+		{
+			name:     "RemoveAdminRole, not present",
+			call:     func(db *DB) error { return db.RemoveAdminRole(context.Background(), "ops") },
+			expected: initial(),
+		},
+		{
+			name: "AddMember",
+			call: func(db *DB) error { return db.AddMember(context.Background(), "carol") },
+			expected: &driver.Security{
+				Admins:  driver.Members{Names: []string{"bob"}, Roles: []string{"boss"}},
+				Members: driver.Members{Names: []string{"alice", "carol"}, Roles: []string{"staff"}},
+			},
+			writes: 1,
+		},
+		{
+			name: "RemoveMember",
+			call: func(db *DB) error { return db.RemoveMember(context.Background(), "alice") },
+			expected: &driver.Security{
+				Admins:  driver.Members{Names: []string{"bob"}, Roles: []string{"boss"}},
+				Members: driver.Members{Names: []string{}, Roles: []string{"staff"}},
+			},
+			writes: 1,
+		},
+		{
+			name: "AddMemberRole",
+			call: func(db *DB) error { return db.AddMemberRole(context.Background(), "guest") },
+			expected: &driver.Security{
+				Admins:  driver.Members{Names: []string{"bob"}, Roles: []string{"boss"}},
+				Members: driver.Members{Names: []string{"alice"}, Roles: []string{"staff", "guest"}},
+			},
+			writes: 1,
+		},
+		{
+			name: "RemoveMemberRole",
+			call: func(db *DB) error { return db.RemoveMemberRole(context.Background(), "staff") },
+			expected: &driver.Security{
+				Admins:  driver.Members{Names: []string{"bob"}, Roles: []string{"boss"}},
+				Members: driver.Members{Names: []string{"alice"}, Roles: []string{}},
+			},
+			writes: 1,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sec := initial()
+			var writes int
+			db := &DB{driverDB: securityDB(sec, &writes, nil)}
+			err := test.call(db)
+			testy.StatusError(t, test.err, test.status, err)
+			if d := diff.Interface(test.expected, sec); d != nil {
+				t.Error(d)
+			}
+			if writes != test.writes {
+				t.Errorf("Unexpected number of writes: %d", writes)
+			}
+		})
+	}
+}
+
+func TestSecurityJSON(t *testing.T) {
+	input := `{"admins":{"names":["bob"]},"members":{},"couchdb_auth_only":true}`
+	var sec Security
+	if err := json.Unmarshal([]byte(input), &sec); err != nil {
+		t.Fatal(err)
+	}
+	expected := Security{
+		Admins: Members{Names: []string{"bob"}},
+		Extra:  map[string]interface{}{"couchdb_auth_only": true},
+	}
+	if d := diff.Interface(expected, sec); d != nil {
+		t.Error(d)
+	}
+	output, err := json.Marshal(sec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := diff.JSON([]byte(input), output); d != nil {
+		t.Error(d)
+	}
+}
+
+func TestUpdateSecurity(t *testing.T) {
+	t.Run("read error", func(t *testing.T) {
+		db := &DB{driverDB: &mock.DB{
+			SecurityFunc: func(_ context.Context) (*driver.Security, error) {
+				return nil, errors.Status(StatusForbidden, "read error")
+			},
+		}}
+		_, err := db.UpdateSecurity(context.Background(), func(_ *Security) error { return nil })
+		testy.StatusError(t, "read error", StatusForbidden, err)
+	})
+	t.Run("callback error", func(t *testing.T) {
+		var writes int
+		db := &DB{driverDB: securityDB(&driver.Security{}, &writes, nil)}
+		_, err := db.UpdateSecurity(context.Background(), func(_ *Security) error {
+			return errors.Status(StatusBadRequest, "callback error")
+		})
+		testy.StatusError(t, "callback error", StatusBadRequest, err)
+	})
+	t.Run("write error", func(t *testing.T) {
+		db := &DB{driverDB: &mock.DB{
+			SecurityFunc: func(_ context.Context) (*driver.Security, error) {
+				return &driver.Security{}, nil
+			},
+			SetSecurityFunc: func(_ context.Context, _ *driver.Security) error {
+				return errors.Status(StatusUnauthorized, "write error")
+			},
+		}}
+		err := db.AddMember(context.Background(), "alice")
+		testy.StatusError(t, "write error", StatusUnauthorized, err)
+	})
+	t.Run("concurrent update retried", func(t *testing.T) {
+		var writes int
+		sec := &driver.Security{}
+		// The first write is clobbered by another client, who adds an admin.
+		interfere := func(s *driver.Security) {
+			if writes == 1 {
+				*s = driver.Security{Admins: driver.Members{Names: []string{"bob"}}}
+			}
+		}
+		db := &DB{driverDB: securityDB(sec, &writes, interfere)}
+		result, err := db.UpdateSecurity(context.Background(), func(s *Security) error {
+			s.Members.Names = addString(s.Members.Names, "alice")
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := &Security{
+			Admins:  Members{Names: []string{"bob"}},
+			Members: Members{Names: []string{"alice"}},
+		}
+		if d := diff.Interface(expected, result); d != nil {
+			t.Error(d)
+		}
+		if writes != 2 {
+			t.Errorf("Unexpected number of writes: %d", writes)
+		}
+	})
+	t.Run("unmodelled fields preserved", func(t *testing.T) {
+		var writes int
+		sec := &driver.Security{
+			Admins: driver.Members{Names: []string{"bob"}},
+			Extra:  map[string]interface{}{"couchdb_auth_only": true},
+		}
+		db := &DB{driverDB: securityDB(sec, &writes, nil)}
+		if err := db.AddMember(context.Background(), "alice"); err != nil {
+			t.Fatal(err)
+		}
+		expected := &driver.Security{
+			Admins:  driver.Members{Names: []string{"bob"}},
+			Members: driver.Members{Names: []string{"alice"}},
+			Extra:   map[string]interface{}{"couchdb_auth_only": true},
+		}
+		if d := diff.Interface(expected, sec); d != nil {
+			t.Error(d)
+		}
+	})
+	t.Run("persistent conflict", func(t *testing.T) {
+		var writes int
+		interfere := func(s *driver.Security) {
+			*s = driver.Security{}
+		}
+		db := &DB{driverDB: securityDB(&driver.Security{}, &writes, interfere)}
+		err := db.AddMember(context.Background(), "alice")
+		if writes != maxSecurityAttempts {
+			t.Errorf("Unexpected number of writes: %d", writes)
+		}
+		testy.StatusError(t, "kivik: security document modified concurrently", StatusConflict, err)
+	})
+}
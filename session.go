@@ -3,6 +3,7 @@ package kivik
 import (
 	"context"
 	"encoding/json"
+	"time"
 
 	"github.com/go-kivik/kivik/driver"
 	"github.com/go-kivik/kivik/errors"
@@ -26,17 +27,83 @@ type Session struct {
 	// RawResponse is the raw JSON response sent by the server, useful for
 	// custom backends which may provide additional fields.
 	RawResponse json.RawMessage
+	// Expires is the time at which the session expires, such as determined by
+	// the Max-Age or Expires attributes of a session cookie. It is the zero
+	// value if unknown, or if the session does not expire.
+	Expires time.Time
 }
 
 // Session returns information about the currently authenticated user.
+//
+// If the client was authenticated with Authenticate, and the session has
+// expired, the client is re-authenticated with the same authenticator, and
+// the request retried. A session is considered expired if its last reported
+// expiry time has passed, if the server responds with StatusUnauthorized, or
+// if the server reports no authenticated user, as CouchDB does for an expired
+// session cookie.
 func (c *Client) Session(ctx context.Context) (*Session, error) {
-	if sessioner, ok := c.driverClient.(driver.Sessioner); ok {
-		session, err := sessioner.Session(ctx)
-		if err != nil {
+	sessioner, ok := c.driverClient.(driver.Sessioner)
+	if !ok {
+		return nil, errors.Status(StatusNotImplemented, "kivik: driver does not support sessions")
+	}
+	auth, expires := c.storedAuth()
+	if auth != nil && !expires.IsZero() && time.Now().After(expires) {
+		if err := c.Authenticate(ctx, auth); err != nil {
 			return nil, err
 		}
-		var ses Session = Session(*session)
-		return &ses, nil
 	}
-	return nil, errors.Status(StatusNotImplemented, "kivik: driver does not support sessions")
+	session, err := sessioner.Session(ctx)
+	if auth != nil && (StatusCode(err) == StatusUnauthorized || (err == nil && session.Name == "")) {
+		if e := c.Authenticate(ctx, auth); e != nil {
+			return nil, e
+		}
+		session, err = sessioner.Session(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+	c.authMu.Lock()
+	c.sessionExpires = session.Expires
+	c.authMu.Unlock()
+	var ses Session = Session(*session)
+	return &ses, nil
+}
+
+// renewSession re-authenticates the client with the authenticator retained by
+// Authenticate, if err has status StatusUnauthorized. It returns true if the
+// session was renewed, and the failed request should be retried once.
+func (c *Client) renewSession(ctx context.Context, err error) bool {
+	if c == nil || StatusCode(err) != StatusUnauthorized {
+		return false
+	}
+	auth, _ := c.storedAuth()
+	if auth == nil {
+		return false
+	}
+	return c.Authenticate(ctx, auth) == nil
+}
+
+func (c *Client) storedAuth() (interface{}, time.Time) {
+	c.authMu.Lock()
+	defer c.authMu.Unlock()
+	return c.auth, c.sessionExpires
+}
+
+// Logout ends the current authenticated session, and discards the
+// authenticator retained by Authenticate.
+//
+// See http://docs.couchdb.org/en/2.1.1/api/server/authn.html#delete--_session
+func (c *Client) Logout(ctx context.Context) error {
+	logouter, ok := c.driverClient.(driver.Logouter)
+	if !ok {
+		return errors.Status(StatusNotImplemented, "kivik: driver does not support logout")
+	}
+	if err := logouter.Logout(ctx); err != nil {
+		return err
+	}
+	c.authMu.Lock()
+	c.auth = nil
+	c.sessionExpires = time.Time{}
+	c.authMu.Unlock()
+	return nil
 }
@@ -4,9 +4,12 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/flimzy/diff"
+	"github.com/flimzy/testy"
 	"github.com/go-kivik/kivik/driver"
+	kerrors "github.com/go-kivik/kivik/errors"
 	"github.com/go-kivik/kivik/mock"
 )
 
@@ -70,3 +73,285 @@ func TestSession(t *testing.T) {
 		})
 	}
 }
+
+// renewingSessioner mocks a driver which supports both sessions and
+// authentication.
+type renewingSessioner struct {
+	*mock.Sessioner
+	AuthenticateFunc func(context.Context, interface{}) error
+}
+
+func (s *renewingSessioner) Authenticate(ctx context.Context, a interface{}) error {
+	return s.AuthenticateFunc(ctx, a)
+}
+
+func TestSessionRenewal(t *testing.T) {
+	t.Run("expired session", func(t *testing.T) {
+		var authCount, sessionCount int
+		client := &Client{
+			driverClient: &renewingSessioner{
+				Sessioner: &mock.Sessioner{
+					SessionFunc: func(_ context.Context) (*driver.Session, error) {
+						sessionCount++
+						if authCount < 2 {
+							return nil, errors.New("unexpected session request")
+						}
+						return &driver.Session{Name: "bob", Expires: time.Now().Add(time.Hour)}, nil
+					},
+				},
+				AuthenticateFunc: func(_ context.Context, _ interface{}) error {
+					authCount++
+					return nil
+				},
+			},
+		}
+		if err := client.Authenticate(context.Background(), CookieAuth{Username: "bob"}); err != nil {
+			t.Fatal(err)
+		}
+		client.sessionExpires = time.Now().Add(-time.Minute)
+		session, err := client.Session(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if session.Name != "bob" {
+			t.Errorf("Unexpected session: %v", session)
+		}
+		if authCount != 2 || sessionCount != 1 {
+			t.Errorf("Unexpected counts: %d authentications, %d sessions", authCount, sessionCount)
+		}
+	})
+	t.Run("unauthorized", func(t *testing.T) {
+		var authCount, sessionCount int
+		client := &Client{
+			driverClient: &renewingSessioner{
+				Sessioner: &mock.Sessioner{
+					SessionFunc: func(_ context.Context) (*driver.Session, error) {
+						sessionCount++
+						if authCount < 2 {
+							return nil, kerrors.Status(StatusUnauthorized, "session expired")
+						}
+						return &driver.Session{Name: "bob"}, nil
+					},
+				},
+				AuthenticateFunc: func(_ context.Context, _ interface{}) error {
+					authCount++
+					return nil
+				},
+			},
+		}
+		if err := client.Authenticate(context.Background(), CookieAuth{Username: "bob"}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := client.Session(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		if authCount != 2 || sessionCount != 2 {
+			t.Errorf("Unexpected counts: %d authentications, %d sessions", authCount, sessionCount)
+		}
+	})
+	t.Run("no user", func(t *testing.T) {
+		var authCount, sessionCount int
+		client := &Client{
+			driverClient: &renewingSessioner{
+				Sessioner: &mock.Sessioner{
+					SessionFunc: func(_ context.Context) (*driver.Session, error) {
+						sessionCount++
+						if authCount < 2 {
+							return &driver.Session{}, nil
+						}
+						return &driver.Session{Name: "bob"}, nil
+					},
+				},
+				AuthenticateFunc: func(_ context.Context, _ interface{}) error {
+					authCount++
+					return nil
+				},
+			},
+		}
+		if err := client.Authenticate(context.Background(), CookieAuth{Username: "bob"}); err != nil {
+			t.Fatal(err)
+		}
+		session, err := client.Session(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if session.Name != "bob" {
+			t.Errorf("Unexpected session: %v", session)
+		}
+		if authCount != 2 || sessionCount != 2 {
+			t.Errorf("Unexpected counts: %d authentications, %d sessions", authCount, sessionCount)
+		}
+	})
+	t.Run("unauthorized, not authenticated", func(t *testing.T) {
+		client := &Client{
+			driverClient: &mock.Sessioner{
+				SessionFunc: func(_ context.Context) (*driver.Session, error) {
+					return nil, kerrors.Status(StatusUnauthorized, "unauthorized")
+				},
+			},
+		}
+		_, err := client.Session(context.Background())
+		testy.StatusError(t, "unauthorized", StatusUnauthorized, err)
+	})
+	t.Run("renewal fails", func(t *testing.T) {
+		var authCount int
+		client := &Client{
+			driverClient: &renewingSessioner{
+				Sessioner: &mock.Sessioner{
+					SessionFunc: func(_ context.Context) (*driver.Session, error) {
+						return nil, kerrors.Status(StatusUnauthorized, "session expired")
+					},
+				},
+				AuthenticateFunc: func(_ context.Context, _ interface{}) error {
+					authCount++
+					if authCount > 1 {
+						return kerrors.Status(StatusUnauthorized, "bad password")
+					}
+					return nil
+				},
+			},
+		}
+		if err := client.Authenticate(context.Background(), CookieAuth{Username: "bob"}); err != nil {
+			t.Fatal(err)
+		}
+		_, err := client.Session(context.Background())
+		testy.StatusError(t, "bad password", StatusUnauthorized, err)
+	})
+}
+
+func TestRequestRenewal(t *testing.T) {
+	newClient := func(authCount *int, authErr error) *Client {
+		client := &Client{
+			driverClient: &renewingSessioner{
+				Sessioner: &mock.Sessioner{
+					Client: &mock.Client{
+						AllDBsFunc: func(_ context.Context, _ map[string]interface{}) ([]string, error) {
+							if *authCount < 2 {
+								return nil, kerrors.Status(StatusUnauthorized, "session expired")
+							}
+							return []string{"foo"}, nil
+						},
+					},
+				},
+				AuthenticateFunc: func(_ context.Context, _ interface{}) error {
+					*authCount++
+					if *authCount > 1 {
+						return authErr
+					}
+					return nil
+				},
+			},
+		}
+		if err := client.Authenticate(context.Background(), CookieAuth{Username: "bob"}); err != nil {
+			t.Fatal(err)
+		}
+		return client
+	}
+	t.Run("client request", func(t *testing.T) {
+		var authCount int
+		client := newClient(&authCount, nil)
+		dbs, err := client.AllDBs(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d := diff.Interface([]string{"foo"}, dbs); d != nil {
+			t.Error(d)
+		}
+		if authCount != 2 {
+			t.Errorf("Unexpected authentications: %d", authCount)
+		}
+	})
+	t.Run("renewal fails", func(t *testing.T) {
+		var authCount int
+		client := newClient(&authCount, kerrors.Status(StatusUnauthorized, "bad password"))
+		_, err := client.AllDBs(context.Background())
+		testy.StatusError(t, "session expired", StatusUnauthorized, err)
+	})
+	t.Run("db request", func(t *testing.T) {
+		var authCount, getCount int
+		db := &DB{
+			client: newClient(&authCount, nil),
+			driverDB: &mock.DB{
+				GetFunc: func(_ context.Context, _ string, _ map[string]interface{}) (*driver.Document, error) {
+					getCount++
+					if authCount < 2 {
+						return nil, kerrors.Status(StatusUnauthorized, "session expired")
+					}
+					return &driver.Document{Rev: "1-x", Body: body(`{}`)}, nil
+				},
+			},
+		}
+		row := db.Get(context.Background(), "foo")
+		if row.Err != nil {
+			t.Fatal(row.Err)
+		}
+		if authCount != 2 || getCount != 2 {
+			t.Errorf("Unexpected counts: %d authentications, %d gets", authCount, getCount)
+		}
+	})
+	t.Run("not authenticated", func(t *testing.T) {
+		var getCount int
+		db := &DB{
+			client: &Client{},
+			driverDB: &mock.DB{
+				GetFunc: func(_ context.Context, _ string, _ map[string]interface{}) (*driver.Document, error) {
+					getCount++
+					return nil, kerrors.Status(StatusUnauthorized, "unauthorized")
+				},
+			},
+		}
+		row := db.Get(context.Background(), "foo")
+		if getCount != 1 {
+			t.Errorf("Unexpected gets: %d", getCount)
+		}
+		testy.StatusError(t, "unauthorized", StatusUnauthorized, row.Err)
+	})
+}
+
+func TestLogout(t *testing.T) {
+	tests := []struct {
+		name   string
+		client driver.Client
+		status int
+		err    string
+	}{
+		{
+			name:   "not supported",
+			client: &mock.Client{},
+			status: StatusNotImplemented,
+			err:    "kivik: driver does not support logout",
+		},
+		{
+			name: "error",
+			client: &mock.Logouter{
+				LogoutFunc: func(_ context.Context) error {
+					return kerrors.Status(StatusBadRequest, "logout error")
+				},
+			},
+			status: StatusBadRequest,
+			err:    "logout error",
+		},
+		{
+			name: "success",
+			client: &mock.Logouter{
+				LogoutFunc: func(_ context.Context) error {
+					return nil
+				},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := &Client{
+				driverClient:   test.client,
+				auth:           CookieAuth{Username: "bob"},
+				sessionExpires: time.Now(),
+			}
+			err := client.Logout(context.Background())
+			testy.StatusError(t, test.err, test.status, err)
+			if client.auth != nil || !client.sessionExpires.IsZero() {
+				t.Errorf("Stored authentication not cleared")
+			}
+		})
+	}
+}
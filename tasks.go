@@ -0,0 +1,131 @@
+package kivik
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/go-kivik/kivik/driver"
+	"github.com/go-kivik/kivik/errors"
+)
+
+// ActiveTask describes a task running on the server, such as a compaction,
+// indexer or replication.
+type ActiveTask struct {
+	// Type is the task type, such as "database_compaction", "indexer",
+	// "view_compaction" or "replication".
+	Type string
+	// Database is the database the task operates on.
+	Database string
+	// Node is the name of the cluster node running the task.
+	Node string
+	// PID is the Erlang process ID of the task.
+	PID string
+	// Progress is the completion percentage, from 0 to 100.
+	Progress int
+	// ChangesDone is the number of changes processed so far.
+	ChangesDone int64
+	// TotalChanges is the total number of changes to be processed.
+	TotalChanges int64
+	// DesignDocument is the design document being indexed or compacted, if
+	// any.
+	DesignDocument string
+	// DocID is the ID of the replication document, for replications managed
+	// by the _replicator database.
+	DocID string
+	// ReplicationID is the replication ID, for replications.
+	ReplicationID string
+	// Source is the replication source, for replications.
+	Source string
+	// Target is the replication target, for replications.
+	Target string
+	// StartedOn is the time the task was started.
+	StartedOn time.Time
+	// UpdatedOn is the time the task was last updated.
+	UpdatedOn time.Time
+	// RawResponse is the raw JSON response sent by the server, useful for
+	// task types which provide additional fields.
+	RawResponse json.RawMessage
+}
+
+// ActiveTasks returns the list of tasks currently running on the server.
+//
+// See http://docs.couchdb.org/en/2.1.1/api/server/common.html#active-tasks
+func (c *Client) ActiveTasks(ctx context.Context) ([]*ActiveTask, error) {
+	tasker, ok := c.driverClient.(driver.ActiveTasker)
+	if !ok {
+		return nil, errors.Status(StatusNotImplemented, "kivik: driver does not support active tasks")
+	}
+	tasks, err := tasker.ActiveTasks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*ActiveTask, len(tasks))
+	for i, task := range tasks {
+		t := ActiveTask(*task)
+		result[i] = &t
+	}
+	return result, nil
+}
+
+// NodeStats contains the statistics reported by a server node.
+type NodeStats struct {
+	// RawResponse is the raw JSON statistics object sent by the server.
+	RawResponse json.RawMessage
+}
+
+// Metric is a single server statistic.
+type Metric struct {
+	// Type is the metric type, one of "counter", "gauge" or "histogram".
+	Type string `json:"type"`
+	// Description describes the metric.
+	Description string `json:"desc"`
+	// Value is the value of the metric. It is a number for counters and
+	// gauges, and an object for histograms.
+	Value json.RawMessage `json:"value"`
+}
+
+// Metric returns the metric at the given path, such as
+// Metric("couchdb", "database_reads"). A status of StatusNotFound is returned
+// if no such metric exists.
+func (s *NodeStats) Metric(path ...string) (*Metric, error) {
+	if len(path) == 0 {
+		return nil, missingArg("path")
+	}
+	obj := s.RawResponse
+	for _, key := range path {
+		var m map[string]json.RawMessage
+		if err := json.Unmarshal(obj, &m); err != nil {
+			return nil, errors.WrapStatus(StatusBadResponse, err)
+		}
+		var ok bool
+		if obj, ok = m[key]; !ok {
+			return nil, errors.Statusf(StatusNotFound, "kivik: stat %s not found", strings.Join(path, "/"))
+		}
+	}
+	metric := &Metric{}
+	if err := json.Unmarshal(obj, metric); err != nil {
+		return nil, errors.WrapStatus(StatusBadResponse, err)
+	}
+	return metric, nil
+}
+
+// Stats returns the statistics of the specified node. Use LocalNode to refer
+// to the node handling the request.
+//
+// See http://docs.couchdb.org/en/2.1.1/api/server/common.html#node-node-name-stats
+func (c *Client) Stats(ctx context.Context, node string) (*NodeStats, error) {
+	if node == "" {
+		return nil, missingArg("node")
+	}
+	statser, ok := c.driverClient.(driver.NodeStatser)
+	if !ok {
+		return nil, errors.Status(StatusNotImplemented, "kivik: driver does not support node stats")
+	}
+	raw, err := statser.NodeStats(ctx, node)
+	if err != nil {
+		return nil, err
+	}
+	return &NodeStats{RawResponse: raw}, nil
+}
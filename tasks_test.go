@@ -0,0 +1,173 @@
+package kivik
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/flimzy/diff"
+	"github.com/flimzy/testy"
+	"github.com/go-kivik/kivik/driver"
+	"github.com/go-kivik/kivik/errors"
+	"github.com/go-kivik/kivik/mock"
+)
+
+func TestActiveTasks(t *testing.T) {
+	started := time.Unix(1376116576, 0).UTC()
+	tests := []struct {
+		name     string
+		client   driver.Client
+		expected []*ActiveTask
+		status   int
+		err      string
+	}{
+		{
+			name:   "not supported",
+			client: &mock.Client{},
+			status: StatusNotImplemented,
+			err:    "kivik: driver does not support active tasks",
+		},
+		{
+			name: "error",
+			client: &mock.ActiveTasker{
+				ActiveTasksFunc: func(_ context.Context) ([]*driver.ActiveTask, error) {
+					return nil, errors.Status(StatusForbidden, "tasks error")
+				},
+			},
+			status: StatusForbidden,
+			err:    "tasks error",
+		},
+		{
+			name: "success",
+			client: &mock.ActiveTasker{
+				ActiveTasksFunc: func(_ context.Context) ([]*driver.ActiveTask, error) {
+					return []*driver.ActiveTask{
+						{Type: "database_compaction", Database: "foo", Progress: 50, ChangesDone: 100, TotalChanges: 200, StartedOn: started},
+						{Type: "indexer", Database: "bar", DesignDocument: "_design/baz", StartedOn: started},
+					}, nil
+				},
+			},
+			expected: []*ActiveTask{
+				{Type: "database_compaction", Database: "foo", Progress: 50, ChangesDone: 100, TotalChanges: 200, StartedOn: started},
+				{Type: "indexer", Database: "bar", DesignDocument: "_design/baz", StartedOn: started},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := &Client{driverClient: test.client}
+			result, err := client.ActiveTasks(context.Background())
+			testy.StatusError(t, test.err, test.status, err)
+			if d := diff.Interface(test.expected, result); d != nil {
+				t.Error(d)
+			}
+		})
+	}
+}
+
+func TestClientStats(t *testing.T) {
+	tests := []struct {
+		name     string
+		client   driver.Client
+		node     string
+		expected *NodeStats
+		status   int
+		err      string
+	}{
+		{
+			name:   "missing node",
+			client: &mock.NodeStatser{},
+			status: StatusBadRequest,
+			err:    "kivik: node required",
+		},
+		{
+			name:   "not supported",
+			client: &mock.Client{},
+			node:   LocalNode,
+			status: StatusNotImplemented,
+			err:    "kivik: driver does not support node stats",
+		},
+		{
+			name: "success",
+			client: &mock.NodeStatser{
+				NodeStatsFunc: func(_ context.Context, node string) (json.RawMessage, error) {
+					if node != LocalNode {
+						return nil, fmt.Errorf("Unexpected node: %s", node)
+					}
+					return json.RawMessage(`{"couchdb":{}}`), nil
+				},
+			},
+			node:     LocalNode,
+			expected: &NodeStats{RawResponse: json.RawMessage(`{"couchdb":{}}`)},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := &Client{driverClient: test.client}
+			result, err := client.Stats(context.Background(), test.node)
+			testy.StatusError(t, test.err, test.status, err)
+			if d := diff.Interface(test.expected, result); d != nil {
+				t.Error(d)
+			}
+		})
+	}
+}
+
+func TestNodeStatsMetric(t *testing.T) {
+	stats := &NodeStats{RawResponse: json.RawMessage(`{
+		"couchdb": {
+			"database_reads": {"value": 42, "type": "counter", "desc": "number of times a document was read"},
+			"open_databases": {"value": 3, "type": "gauge", "desc": "number of open databases"}
+		}
+	}`)}
+	tests := []struct {
+		name     string
+		stats    *NodeStats
+		path     []string
+		expected *Metric
+		status   int
+		err      string
+	}{
+		{
+			name:   "no path",
+			stats:  stats,
+			status: StatusBadRequest,
+			err:    "kivik: path required",
+		},
+		{
+			name:   "not found",
+			stats:  stats,
+			path:   []string{"couchdb", "foo"},
+			status: StatusNotFound,
+			err:    "kivik: stat couchdb/foo not found",
+		},
+		{
+			name:   "invalid JSON",
+			stats:  &NodeStats{RawResponse: json.RawMessage(`{`)},
+			path:   []string{"couchdb"},
+			status: StatusBadResponse,
+			err:    "unexpected end of JSON input",
+		},
+		{
+			name:  "counter",
+			stats: stats,
+			path:  []string{"couchdb", "database_reads"},
+			expected: &Metric{
+				Type:        "counter",
+				Description: "number of times a document was read",
+				Value:       json.RawMessage("42"),
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := test.stats.Metric(test.path...)
+			testy.StatusError(t, test.err, test.status, err)
+			if d := diff.Interface(test.expected, result); d != nil {
+				t.Error(d)
+			}
+		})
+	}
+}
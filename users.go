@@ -0,0 +1,175 @@
+package kivik
+
+import (
+	"context"
+	"strings"
+)
+
+// userType is the required value of the type field of a user document.
+const userType = "user"
+
+// User represents a user document in the _users database.
+type User struct {
+	// ID is the document ID, which is the user's name prefixed with
+	// UserPrefix.
+	ID string `json:"_id"`
+	// Rev is the document revision.
+	Rev string `json:"_rev,omitempty"`
+	// Name is the user's login name.
+	Name string `json:"name"`
+	// Type is always "user".
+	Type string `json:"type"`
+	// Roles is the list of roles the user holds.
+	Roles []string `json:"roles"`
+	// Password is the user's plain-text password. It is only ever set when
+	// writing a user document, as the server replaces it with a derived key.
+	Password string `json:"password,omitempty"`
+	// PasswordScheme, Iterations, DerivedKey and Salt describe the stored
+	// password hash.
+	PasswordScheme string `json:"password_scheme,omitempty"`
+	Iterations     int    `json:"iterations,omitempty"`
+	DerivedKey     string `json:"derived_key,omitempty"`
+	Salt           string `json:"salt,omitempty"`
+}
+
+// userID returns the document ID for the named user.
+func userID(name string) string {
+	return UserPrefix + name
+}
+
+func (c *Client) usersDB(ctx context.Context) (*DB, error) {
+	return c.DB(ctx, UsersDB)
+}
+
+// CreateUser creates a user in the _users database, with the given password
+// and roles, returning the new document revision.
+//
+// See http://docs.couchdb.org/en/2.0.0/intro/security.html#creating-new-user
+func (c *Client) CreateUser(ctx context.Context, name, password string, roles ...string) (rev string, err error) {
+	if name == "" {
+		return "", missingArg("name")
+	}
+	if password == "" {
+		return "", missingArg("password")
+	}
+	db, err := c.usersDB(ctx)
+	if err != nil {
+		return "", err
+	}
+	if roles == nil {
+		roles = []string{}
+	}
+	return db.Put(ctx, userID(name), &User{
+		ID:       userID(name),
+		Name:     name,
+		Type:     userType,
+		Roles:    roles,
+		Password: password,
+	})
+}
+
+// GetUser fetches the named user from the _users database.
+func (c *Client) GetUser(ctx context.Context, name string) (*User, error) {
+	if name == "" {
+		return nil, missingArg("name")
+	}
+	db, err := c.usersDB(ctx)
+	if err != nil {
+		return nil, err
+	}
+	user := &User{}
+	if err := db.Get(ctx, userID(name)).ScanDoc(user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// UpdateUserRoles replaces the roles of the named user, returning the new
+// document revision. Other fields of the user document are preserved.
+func (c *Client) UpdateUserRoles(ctx context.Context, name string, roles ...string) (newRev string, err error) {
+	if roles == nil {
+		roles = []string{}
+	}
+	return c.updateUser(ctx, name, func(doc map[string]interface{}) {
+		doc["roles"] = roles
+	})
+}
+
+// ChangePassword sets the password of the named user, returning the new
+// document revision. Other fields of the user document are preserved.
+//
+// See http://docs.couchdb.org/en/2.0.0/intro/security.html#password-changing
+func (c *Client) ChangePassword(ctx context.Context, name, password string) (newRev string, err error) {
+	if password == "" {
+		return "", missingArg("password")
+	}
+	return c.updateUser(ctx, name, func(doc map[string]interface{}) {
+		doc["password"] = password
+	})
+}
+
+// updateUser reads the named user document, applies fn, and writes the
+// result. A map is used, rather than a User, so that fields unknown to User
+// are preserved.
+func (c *Client) updateUser(ctx context.Context, name string, fn func(map[string]interface{})) (string, error) {
+	if name == "" {
+		return "", missingArg("name")
+	}
+	db, err := c.usersDB(ctx)
+	if err != nil {
+		return "", err
+	}
+	var doc map[string]interface{}
+	if err := db.Get(ctx, userID(name)).ScanDoc(&doc); err != nil {
+		return "", err
+	}
+	fn(doc)
+	return db.Put(ctx, userID(name), doc)
+}
+
+// DeleteUser deletes the named user from the _users database.
+func (c *Client) DeleteUser(ctx context.Context, name string) error {
+	if name == "" {
+		return missingArg("name")
+	}
+	db, err := c.usersDB(ctx)
+	if err != nil {
+		return err
+	}
+	_, rev, err := db.GetMeta(ctx, userID(name))
+	if err != nil {
+		return err
+	}
+	_, err = db.Delete(ctx, userID(name), rev)
+	return err
+}
+
+// ListUsers returns all users in the _users database, sorted by name. Other
+// documents in the database, such as design documents, are ignored.
+func (c *Client) ListUsers(ctx context.Context) ([]*User, error) {
+	db, err := c.usersDB(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := db.AllDocs(ctx, Options{
+		"include_docs": true,
+		"startkey":     UserPrefix,
+		"endkey":       UserPrefix + EndKeySuffix,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close() // nolint: errcheck
+	var users []*User
+	for rows.Next() {
+		if !strings.HasPrefix(rows.ID(), UserPrefix) {
+			continue
+		}
+		user := &User{}
+		if err := rows.ScanDoc(user); err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+	return users, rows.Err()
+}
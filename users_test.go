@@ -0,0 +1,356 @@
+package kivik
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/flimzy/diff"
+	"github.com/flimzy/testy"
+	"github.com/go-kivik/kivik/driver"
+	"github.com/go-kivik/kivik/errors"
+	"github.com/go-kivik/kivik/mock"
+)
+
+// usersClient returns a client whose _users database is db.
+func usersClient(db driver.DB) *Client {
+	return &Client{
+		driverClient: &mock.Client{
+			DBFunc: func(_ context.Context, dbName string, _ map[string]interface{}) (driver.DB, error) {
+				if dbName != UsersDB {
+					return nil, fmt.Errorf("Unexpected db name: %s", dbName)
+				}
+				return db, nil
+			},
+		},
+	}
+}
+
+const bobDoc = `{"_id":"org.couchdb.user:bob","_rev":"1-xxx","name":"bob","type":"user","roles":["staff"],"password_scheme":"pbkdf2","iterations":10,"derived_key":"abc","salt":"def","email":"bob@example.com"}`
+
+func bobDB(puts *[]string) *mock.DB {
+	return &mock.DB{
+		GetFunc: func(_ context.Context, docID string, _ map[string]interface{}) (*driver.Document, error) {
+			if docID != "org.couchdb.user:bob" {
+				return nil, errors.Status(StatusNotFound, "missing")
+			}
+			return &driver.Document{Body: body(bobDoc)}, nil
+		},
+		PutFunc: func(_ context.Context, docID string, doc interface{}, _ map[string]interface{}) (string, error) {
+			data, err := json.Marshal(doc)
+			if err != nil {
+				return "", err
+			}
+			*puts = append(*puts, docID+" "+string(data))
+			return "2-xxx", nil
+		},
+	}
+}
+
+func TestCreateUser(t *testing.T) {
+	tests := []struct {
+		name     string
+		username string
+		password string
+		roles    []string
+		db       driver.DB
+		expected []string
+		newRev   string
+		status   int
+		err      string
+	}{
+		{
+			name:   "missing name",
+			status: StatusBadRequest,
+			err:    "kivik: name required",
+		},
+		{
+			name:     "missing password",
+			username: "bob",
+			status:   StatusBadRequest,
+			err:      "kivik: password required",
+		},
+		{
+			name:     "no roles",
+			username: "alice",
+			password: "abc123",
+			expected: []string{`org.couchdb.user:alice {"_id":"org.couchdb.user:alice","name":"alice","type":"user","roles":[],"password":"abc123"}`},
+			newRev:   "2-xxx",
+		},
+		{
+			name:     "roles",
+			username: "alice",
+			password: "abc123",
+			roles:    []string{"staff", "ops"},
+			expected: []string{`org.couchdb.user:alice {"_id":"org.couchdb.user:alice","name":"alice","type":"user","roles":["staff","ops"],"password":"abc123"}`},
+			newRev:   "2-xxx",
+		},
+		{
+			name:     "conflict",
+			username: "bob",
+			password: "abc123",
+			db: &mock.DB{
+				PutFunc: func(_ context.Context, _ string, _ interface{}, _ map[string]interface{}) (string, error) {
+					return "", errors.Status(StatusConflict, "conflict")
+				},
+			},
+			status: StatusConflict,
+			err:    "conflict",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var puts []string
+			db := test.db
+			if db == nil {
+				db = bobDB(&puts)
+			}
+			newRev, err := usersClient(db).CreateUser(context.Background(), test.username, test.password, test.roles...)
+			testy.StatusError(t, test.err, test.status, err)
+			if newRev != test.newRev {
+				t.Errorf("Unexpected rev: %s", newRev)
+			}
+			if d := diff.Interface(test.expected, puts); d != nil {
+				t.Error(d)
+			}
+		})
+	}
+}
+
+func TestGetUser(t *testing.T) {
+	tests := []struct {
+		name     string
+		username string
+		expected *User
+		status   int
+		err      string
+	}{
+		{
+			name:   "missing name",
+			status: StatusBadRequest,
+			err:    "kivik: name required",
+		},
+		{
+			name:     "not found",
+			username: "alice",
+			status:   StatusNotFound,
+			err:      "missing",
+		},
+		{
+			name:     "success",
+			username: "bob",
+			expected: &User{
+				ID:             "org.couchdb.user:bob",
+				Rev:            "1-xxx",
+				Name:           "bob",
+				Type:           "user",
+				Roles:          []string{"staff"},
+				PasswordScheme: "pbkdf2",
+				Iterations:     10,
+				DerivedKey:     "abc",
+				Salt:           "def",
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var puts []string
+			result, err := usersClient(bobDB(&puts)).GetUser(context.Background(), test.username)
+			testy.StatusError(t, test.err, test.status, err)
+			if d := diff.Interface(test.expected, result); d != nil {
+				t.Error(d)
+			}
+		})
+	}
+}
+
+func TestUpdateUser(t *testing.T) {
+	tests := []struct {
+		name     string
+		call     func(*Client) (string, error)
+		expected []string
+		newRev   string
+		status   int
+		err      string
+	}{
+		{
+			name: "UpdateUserRoles, missing name",
+			call: func(c *Client) (string, error) {
+				return c.UpdateUserRoles(context.Background(), "", "staff")
+			},
+			status: StatusBadRequest,
+			err:    "kivik: name required",
+		},
+		{
+			name: "UpdateUserRoles, not found",
+			call: func(c *Client) (string, error) {
+				return c.UpdateUserRoles(context.Background(), "alice", "staff")
+			},
+			status: StatusNotFound,
+			err:    "missing",
+		},
+		{
+			name: "UpdateUserRoles",
+			call: func(c *Client) (string, error) {
+				return c.UpdateUserRoles(context.Background(), "bob", "admin", "ops")
+			},
+			expected: []string{`org.couchdb.user:bob {"_id":"org.couchdb.user:bob","_rev":"1-xxx","derived_key":"abc","email":"bob@example.com","iterations":10,"name":"bob","password_scheme":"pbkdf2","roles":["admin","ops"],"salt":"def","type":"user"}`},
+			newRev:   "2-xxx",
+		},
+		{
+			name: "UpdateUserRoles, no roles",
+			call: func(c *Client) (string, error) {
+				return c.UpdateUserRoles(context.Background(), "bob")
+			},
+			expected: []string{`org.couchdb.user:bob {"_id":"org.couchdb.user:bob","_rev":"1-xxx","derived_key":"abc","email":"bob@example.com","iterations":10,"name":"bob","password_scheme":"pbkdf2","roles":[],"salt":"def","type":"user"}`},
+			newRev:   "2-xxx",
+		},
+		{
+			name: "ChangePassword, missing password",
+			call: func(c *Client) (string, error) {
+				return c.ChangePassword(context.Background(), "bob", "")
+			},
+			status: StatusBadRequest,
+			err:    "kivik: password required",
+		},
+		{
+			name: "ChangePassword",
+			call: func(c *Client) (string, error) {
+				return c.ChangePassword(context.Background(), "bob", "s3cr3t")
+			},
+			expected: []string{`org.couchdb.user:bob {"_id":"org.couchdb.user:bob","_rev":"1-xxx","derived_key":"abc","email":"bob@example.com","iterations":10,"name":"bob","password":"s3cr3t","password_scheme":"pbkdf2","roles":["staff"],"salt":"def","type":"user"}`},
+			newRev:   "2-xxx",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var puts []string
+			newRev, err := test.call(usersClient(bobDB(&puts)))
+			testy.StatusError(t, test.err, test.status, err)
+			if newRev != test.newRev {
+				t.Errorf("Unexpected rev: %s", newRev)
+			}
+			if d := diff.Interface(test.expected, puts); d != nil {
+				t.Error(d)
+			}
+		})
+	}
+}
+
+func TestDeleteUser(t *testing.T) {
+	tests := []struct {
+		name     string
+		username string
+		db       driver.DB
+		status   int
+		err      string
+	}{
+		{
+			name:   "missing name",
+			status: StatusBadRequest,
+			err:    "kivik: name required",
+		},
+		{
+			name:     "not found",
+			username: "alice",
+			db: &mock.MetaGetter{
+				GetMetaFunc: func(_ context.Context, _ string, _ map[string]interface{}) (int64, string, error) {
+					return 0, "", errors.Status(StatusNotFound, "missing")
+				},
+			},
+			status: StatusNotFound,
+			err:    "missing",
+		},
+		{
+			name:     "success",
+			username: "bob",
+			db: &mock.MetaGetter{
+				GetMetaFunc: func(_ context.Context, docID string, _ map[string]interface{}) (int64, string, error) {
+					return 100, "3-xxx", nil
+				},
+				DB: &mock.DB{
+					DeleteFunc: func(_ context.Context, docID, rev string, _ map[string]interface{}) (string, error) {
+						if docID != "org.couchdb.user:bob" || rev != "3-xxx" {
+							return "", fmt.Errorf("Unexpected delete: %s %s", docID, rev)
+						}
+						return "4-xxx", nil
+					},
+				},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := usersClient(test.db).DeleteUser(context.Background(), test.username)
+			testy.StatusError(t, test.err, test.status, err)
+		})
+	}
+}
+
+func TestListUsers(t *testing.T) {
+	docs := []driver.Row{
+		{ID: "_design/_auth"},
+		{ID: "org.couchdb.user:alice", Doc: json.RawMessage(`{"_id":"org.couchdb.user:alice","name":"alice","type":"user","roles":[]}`)},
+		{ID: "org.couchdb.user:bob", Doc: json.RawMessage(`{"_id":"org.couchdb.user:bob","name":"bob","type":"user","roles":["staff"]}`)},
+	}
+	tests := []struct {
+		name     string
+		db       driver.DB
+		expected []*User
+		status   int
+		err      string
+	}{
+		{
+			name: "all docs error",
+			db: &mock.DB{
+				AllDocsFunc: func(_ context.Context, _ map[string]interface{}) (driver.Rows, error) {
+					return nil, errors.Status(StatusForbidden, "forbidden")
+				},
+			},
+			status: StatusForbidden,
+			err:    "forbidden",
+		},
+		{
+			name: "success",
+			db: &mock.DB{
+				AllDocsFunc: func(_ context.Context, opts map[string]interface{}) (driver.Rows, error) {
+					expectedOpts := map[string]interface{}{
+						"include_docs": true,
+						"startkey":     UserPrefix,
+						"endkey":       UserPrefix + EndKeySuffix,
+					}
+					if d := diff.Interface(expectedOpts, opts); d != nil {
+						return nil, fmt.Errorf("Unexpected options:\n%s", d)
+					}
+					var i int
+					return &mock.Rows{
+						NextFunc: func(row *driver.Row) error {
+							if i == len(docs) {
+								return io.EOF
+							}
+							*row = docs[i]
+							i++
+							return nil
+						},
+						CloseFunc: func() error { return nil },
+					}, nil
+				},
+			},
+			expected: []*User{
+				{ID: "org.couchdb.user:alice", Name: "alice", Type: "user", Roles: []string{}},
+				{ID: "org.couchdb.user:bob", Name: "bob", Type: "user", Roles: []string{"staff"}},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := usersClient(test.db).ListUsers(context.Background())
+			testy.StatusError(t, test.err, test.status, err)
+			if d := diff.Interface(test.expected, result); d != nil {
+				t.Error(d)
+			}
+		})
+	}
+}